@@ -0,0 +1,324 @@
+// eventgen listener/abis altındaki <adres>.abi.json dosyalarından her event için tipli Go struct'ı,
+// çözücü ve varsayılan bildirim biçimleyicisi üretir. Üretilen kod init içinde kendini listener
+// pipeline'ına kaydeder; yeni protokol eventleri elle kod yazmadan zengin mesajla bildirilir.
+// Üretilen tanımlayıcılar paketteki elle yazılmış kodla çakışmasın diye "gen" önekini taşır
+// (genApprovalEvent, decodeGenApprovalEvent, ...); yine de çakışma varsa üretim hata verir.
+//
+//	go generate ./listener
+//	go run ./cmd/eventgen -abis listener/abis -out listener/events_gen.go
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// genField üretilen struct'ın tek alanı
+type genField struct {
+	Name   string // ABI parametre adı (çözülen map anahtarı)
+	GoName string
+	GoType string
+}
+
+// genEvent üretilecek tek event
+type genEvent struct {
+	TypeName string
+	Address  common.Address
+	Sig      string
+	Fragment string
+	Fields   []genField
+}
+
+func main() {
+	abisDir := flag.String("abis", filepath.Join("listener", "abis"), "ABI klasörü (<adres>.abi.json dosyaları)")
+	out := flag.String("out", filepath.Join("listener", "events_gen.go"), "üretilecek Go dosyası")
+	pkg := flag.String("pkg", "listener", "paket adı")
+	flag.Parse()
+
+	events, err := collectEvents(*abisDir)
+	if err != nil {
+		log.Fatalf("❌ ABI'ler okunamadı: %v", err)
+	}
+	if err := checkCollisions(filepath.Dir(*out), *out, events); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	src, err := render(*pkg, events)
+	if err != nil {
+		log.Fatalf("❌ Kod üretilemedi: %v", err)
+	}
+	if err := os.WriteFile(*out, src, 0644); err != nil {
+		log.Fatalf("❌ %s yazılamadı: %v", *out, err)
+	}
+	log.Printf("✅ %d event için çözücü üretildi: %s", len(events), *out)
+}
+
+// collectEvents klasördeki ABI dosyalarını adres ve event adına göre sıralı okur.
+// Aynı ad birden fazla kontratta varsa tip adlarına adres soneki eklenir.
+func collectEvents(dir string) ([]genEvent, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var events []genEvent
+	for _, e := range entries {
+		name := e.Name()
+		lower := strings.ToLower(name)
+		if e.IsDir() || !(strings.HasSuffix(lower, ".json") || strings.HasSuffix(lower, ".abi")) {
+			continue
+		}
+		addrPart := strings.SplitN(name, ".", 2)[0]
+		if !common.IsHexAddress(addrPart) {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		evs, err := fileEvents(common.HexToAddress(addrPart), b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		events = append(events, evs...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].TypeName != events[j].TypeName {
+			return events[i].TypeName < events[j].TypeName
+		}
+		return strings.ToLower(events[i].Address.Hex()) < strings.ToLower(events[j].Address.Hex())
+	})
+
+	count := make(map[string]int)
+	for _, ev := range events {
+		count[ev.TypeName]++
+	}
+	for i := range events {
+		if count[events[i].TypeName] > 1 {
+			events[i].TypeName += strings.ToLower(events[i].Address.Hex()[2:8])
+		}
+		events[i].TypeName += "Event"
+	}
+	return events, nil
+}
+
+// fileEvents tek ABI dosyasındaki anonim olmayan eventleri tek eventlik ABI parçalarıyla döner
+func fileEvents(addr common.Address, b []byte) ([]genEvent, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, err
+	}
+	var out []genEvent
+	seen := make(map[string]int)
+	for _, item := range raw {
+		var head struct {
+			Type      string `json:"type"`
+			Anonymous bool   `json:"anonymous"`
+		}
+		if err := json.Unmarshal(item, &head); err != nil || head.Type != "event" || head.Anonymous {
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, item); err != nil {
+			return nil, err
+		}
+		fragment := "[" + compact.String() + "]"
+		parsed, err := abi.JSON(strings.NewReader(fragment))
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range parsed.Events {
+			// Aynı dosyada aşırı yüklenmiş eventler: genTransfer, genTransfer0, ...
+			base := "gen" + abi.ToCamelCase(ev.RawName)
+			typeName := base
+			if n := seen[base]; n > 0 {
+				typeName = fmt.Sprintf("%s%d", base, n-1)
+			}
+			seen[base]++
+			out = append(out, genEvent{
+				TypeName: typeName,
+				Address:  addr,
+				Sig:      ev.Sig,
+				Fragment: fragment,
+				Fields:   eventFields(ev),
+			})
+		}
+	}
+	return out, nil
+}
+
+// eventFields parametreleri Go alanlarına çevirir. Indexed dinamik tiplerin (string, bytes, dizi, tuple)
+// topic'te yalnızca hash'i bulunduğundan alan tipi common.Hash olur.
+func eventFields(ev abi.Event) []genField {
+	fields := make([]genField, 0, len(ev.Inputs))
+	used := make(map[string]bool)
+	for _, in := range ev.Inputs {
+		goName := abi.ToCamelCase(in.Name)
+		for used[goName] {
+			goName += "_"
+		}
+		used[goName] = true
+		goType := in.Type.GetType().String()
+		if in.Indexed {
+			switch in.Type.T {
+			case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy, abi.FunctionTy:
+				goType = "common.Hash"
+			}
+		}
+		fields = append(fields, genField{Name: in.Name, GoName: goName, GoType: goType})
+	}
+	return fields
+}
+
+// generatedIdents eventin üretilen dosyada paket seviyesinde tanımladığı adları döner
+func generatedIdents(ev genEvent) []string {
+	t := ev.TypeName
+	return []string{t, t + "ABI", "decode" + upperFirst(t), "format" + upperFirst(t)}
+}
+
+// checkCollisions üretilecek adların paketteki diğer dosyaların (out hariç) paket seviyesi
+// tanımlarıyla ya da birbiriyle çakışmadığını doğrular
+func checkCollisions(pkgDir, out string, events []genEvent) error {
+	fset := token.NewFileSet()
+	skip, _ := filepath.Abs(out)
+	pkgs, err := parser.ParseDir(fset, pkgDir, func(fi os.FileInfo) bool {
+		p, _ := filepath.Abs(filepath.Join(pkgDir, fi.Name()))
+		return p != skip && !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return fmt.Errorf("paket okunamadı (%s): %w", pkgDir, err)
+	}
+	declared := make(map[string]string)
+	for _, pkg := range pkgs {
+		for fname, f := range pkg.Files {
+			for _, d := range f.Decls {
+				switch d := d.(type) {
+				case *ast.FuncDecl:
+					if d.Recv == nil {
+						declared[d.Name.Name] = filepath.Base(fname)
+					}
+				case *ast.GenDecl:
+					for _, s := range d.Specs {
+						switch s := s.(type) {
+						case *ast.TypeSpec:
+							declared[s.Name.Name] = filepath.Base(fname)
+						case *ast.ValueSpec:
+							for _, n := range s.Names {
+								declared[n.Name] = filepath.Base(fname)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	for _, ev := range events {
+		for _, id := range generatedIdents(ev) {
+			if where, ok := declared[id]; ok {
+				return fmt.Errorf("%s (%s %s) için üretilen %q adı %s ile çakışıyor", ev.Sig, ev.Address.Hex(), ev.TypeName, id, where)
+			}
+			declared[id] = "üretilen " + ev.TypeName
+		}
+	}
+	return nil
+}
+
+// render dosyayı üretir ve gofmt'ten geçirir
+func render(pkg string, events []genEvent) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("// Code generated by eventgen from listener/abis; DO NOT EDIT.\n\n")
+	b.WriteString("package " + pkg + "\n\n")
+	if len(events) == 0 {
+		b.WriteString("// listener/abis altında ABI bulunamadı; üretilmiş event çözücüsü yok.\n")
+		return format.Source(b.Bytes())
+	}
+
+	usesBig := false
+	for _, ev := range events {
+		for _, f := range ev.Fields {
+			if strings.Contains(f.GoType, "big.") {
+				usesBig = true
+			}
+		}
+	}
+	b.WriteString("import (\n")
+	if usesBig {
+		b.WriteString("\t\"math/big\"\n\n")
+	}
+	b.WriteString("\t\"github.com/ethereum/go-ethereum/common\"\n\t\"github.com/ethereum/go-ethereum/core/types\"\n)\n\n")
+
+	for _, ev := range events {
+		t := ev.TypeName
+		abiVar := t + "ABI"
+		fmt.Fprintf(&b, "// %s %s kontratındaki %s eventi\n", t, ev.Address.Hex(), ev.Sig)
+		fmt.Fprintf(&b, "type %s struct {\n", t)
+		for _, f := range ev.Fields {
+			fmt.Fprintf(&b, "\t%s %s\n", f.GoName, f.GoType)
+		}
+		b.WriteString("}\n\n")
+
+		fmt.Fprintf(&b, "var %s = mustParseGeneratedEvent(%q)\n\n", abiVar, ev.Fragment)
+
+		fmt.Fprintf(&b, "// decode%s logu tipli struct'a çözer\n", upperFirst(t))
+		fmt.Fprintf(&b, "func decode%s(lg types.Log) (*%s, error) {\n", upperFirst(t), t)
+		// Parametresiz eventlerde (Pause gibi) çözülen değer kullanılmaz, yalnızca topic/data doğrulanır
+		valuesVar := "values"
+		if len(ev.Fields) == 0 {
+			valuesVar = "_"
+		}
+		fmt.Fprintf(&b, "\t%s, err := unpackEventLog(%s, lg)\n\tif err != nil {\n\t\treturn nil, err\n\t}\n", valuesVar, abiVar)
+		fmt.Fprintf(&b, "\te := &%s{}\n", t)
+		for _, f := range ev.Fields {
+			fmt.Fprintf(&b, "\te.%s, _ = values[%q].(%s)\n", f.GoName, f.Name, f.GoType)
+		}
+		b.WriteString("\treturn e, nil\n}\n\n")
+
+		fmt.Fprintf(&b, "// values alanları ABI parametre adlarıyla döner\n")
+		fmt.Fprintf(&b, "func (e *%s) values() map[string]interface{} {\n\treturn map[string]interface{}{\n", t)
+		for _, f := range ev.Fields {
+			fmt.Fprintf(&b, "\t\t%q: e.%s,\n", f.Name, f.GoName)
+		}
+		b.WriteString("\t}\n}\n\n")
+
+		fmt.Fprintf(&b, "// format%s varsayılan bildirim biçimi\n", upperFirst(t))
+		fmt.Fprintf(&b, "func format%s(ch *chainInstance, lg types.Log, txd txDetails) (string, string, bool) {\n", upperFirst(t))
+		fmt.Fprintf(&b, "\te, err := decode%s(lg)\n\tif err != nil {\n\t\treturn \"\", \"\", false\n\t}\n", upperFirst(t))
+		fmt.Fprintf(&b, "\treturn renderGeneratedEvent(ch, lg, %s, e.values(), txd)\n}\n\n", abiVar)
+	}
+
+	b.WriteString("func init() {\n")
+	for _, ev := range events {
+		t := ev.TypeName
+		fmt.Fprintf(&b, "\tregisterGeneratedEvent(generatedEvent{\n")
+		fmt.Fprintf(&b, "\t\taddress: common.HexToAddress(%q),\n", ev.Address.Hex())
+		fmt.Fprintf(&b, "\t\tevent:   %sABI,\n", t)
+		fmt.Fprintf(&b, "\t\tvalues: func(lg types.Log) (map[string]interface{}, error) {\n")
+		fmt.Fprintf(&b, "\t\t\te, err := decode%s(lg)\n\t\t\tif err != nil {\n\t\t\t\treturn nil, err\n\t\t\t}\n\t\t\treturn e.values(), nil\n\t\t},\n", upperFirst(t))
+		fmt.Fprintf(&b, "\t\tformat: format%s,\n", upperFirst(t))
+		b.WriteString("\t})\n")
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -6,6 +6,7 @@ import (
 
 	"event-listener-backend/listener"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gin-gonic/gin"
 )
@@ -37,7 +38,10 @@ func SetupAPI() *gin.Engine {
 		})
 	})
 
-	// Balance endpoint'leri
+	// Zincirler
+	r.GET("/chains", handleChains)
+
+	// Balance endpoint'leri (?chain=<isim>, boşsa varsayılan zincir)
 	r.GET("/balance/:token", handleBalance)
 	r.GET("/balance/main", handleMainBalance)
 	r.GET("/balance/main/:token", handleMainTokenBalance)
@@ -45,6 +49,16 @@ func SetupAPI() *gin.Engine {
 	// Daily stats
 	r.GET("/stats/daily", handleDailyStats)
 
+	// Event imza kayıt defteri (özet ve yeniden başlatmadan yeniden yükleme)
+	r.GET("/signatures", handleSignatures)
+	r.POST("/signatures/reload", handleReloadSignatures)
+
+	// İzlenen cüzdanların açık onayları (?chain=<isim>&owner=<adres>, boşsa tümü)
+	r.GET("/approvals", handleApprovals)
+
+	// Token fiyatının kaynak dökümü (?chain=<isim>&refresh=true yeniden hesaplar)
+	r.GET("/prices/:token", handlePriceBreakdown)
+
 	// TEST endpoints (sadece hızlı manuel doğrulama için)
 	r.POST("/test/module-installed", handleTestModuleInstalled)
 
@@ -54,24 +68,15 @@ func SetupAPI() *gin.Engine {
 // handleBalance token balance'ını döner
 func handleBalance(c *gin.Context) {
 	token := strings.ToUpper(c.Param("token"))
+	chain := c.Query("chain")
 
 	// Desteklenen token'ları kontrol et
-	supportedTokens := map[string]bool{
-		"USDT": true,
-		"ETH":  true,
-		"WBTC": true,
-	}
-
-	if !supportedTokens[token] {
-		c.JSON(400, gin.H{
-			"success": false,
-			"error":   "Desteklenmeyen token. Desteklenen: USDT, ETH, WBTC",
-		})
+	if !checkSupportedToken(c, chain, token) {
 		return
 	}
 
 	// Balance'ı al
-	balance, err := listener.GetTokenBalance(token)
+	balance, err := listener.GetTokenBalance(chain, token)
 	if err != nil {
 		c.JSON(500, gin.H{
 			"success": false,
@@ -83,9 +88,29 @@ func handleBalance(c *gin.Context) {
 	c.JSON(200, balance)
 }
 
-// handleMainBalance ana kontratın ETH balance'ını döner
+// checkSupportedToken token'ın zincirde desteklenip desteklenmediğini kontrol eder; değilse 400 döner
+func checkSupportedToken(c *gin.Context, chain, token string) bool {
+	supported := listener.SupportedBalanceTokens(chain)
+	for _, t := range supported {
+		if t == token {
+			return true
+		}
+	}
+	c.JSON(400, gin.H{
+		"success": false,
+		"error":   "Desteklenmeyen token. Desteklenen: " + strings.Join(supported, ", "),
+	})
+	return false
+}
+
+// handleChains etkin zincirleri döner
+func handleChains(c *gin.Context) {
+	c.JSON(200, gin.H{"success": true, "data": listener.GetChains()})
+}
+
+// handleMainBalance ana kontratın native coin balance'ını döner
 func handleMainBalance(c *gin.Context) {
-	balance, err := listener.GetMainBalance()
+	balance, err := listener.GetMainBalance(c.Query("chain"))
 	if err != nil {
 		c.JSON(500, gin.H{
 			"success": false,
@@ -100,23 +125,14 @@ func handleMainBalance(c *gin.Context) {
 // handleMainTokenBalance ana kontratın belirtilen token balance'ını döner
 func handleMainTokenBalance(c *gin.Context) {
 	token := strings.ToUpper(c.Param("token"))
+	chain := c.Query("chain")
 
 	// Desteklenen token'ları kontrol et
-	supportedTokens := map[string]bool{
-		"USDT": true,
-		"ETH":  true,
-		"WBTC": true,
-	}
-
-	if !supportedTokens[token] {
-		c.JSON(400, gin.H{
-			"success": false,
-			"error":   "Desteklenmeyen token. Desteklenen: USDT, ETH, WBTC",
-		})
+	if !checkSupportedToken(c, chain, token) {
 		return
 	}
 
-	balance, err := listener.GetMainTokenBalance(token)
+	balance, err := listener.GetMainTokenBalance(chain, token)
 	if err != nil {
 		c.JSON(500, gin.H{
 			"success": false,
@@ -147,7 +163,7 @@ func handleDailyStats(c *gin.Context) {
 		c.JSON(500, gin.H{"success": false, "error": err.Error()})
 		return
 	}
-	c.JSON(200, gin.H{"success": true, "data": stats})
+	c.JSON(200, gin.H{"success": true, "chain": listener.DefaultChainName(), "data": stats})
 }
 
 // handleTestModuleInstalled: ÖNEMLİ ModuleInstalled test bildirimi yollar
@@ -170,3 +186,56 @@ func listenerTimeNow() string {
 		// Kolay yol: listener tarafındaki formatla aynı olacak şekilde API body'i orada oluşturulsun
 		"")
 }
+
+// handleSignatures yüklü event imzalarının özetini döner
+func handleSignatures(c *gin.Context) {
+	c.JSON(200, gin.H{"success": true, "data": listener.GetSignatureStats()})
+}
+
+// handleReloadSignatures imza dosyalarını ve ABI'leri yeniden yükler; hata varsa mevcut kayıt defteri korunur
+func handleReloadSignatures(c *gin.Context) {
+	stats, err := listener.ReloadSignatures()
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+			"data":    stats,
+		})
+		return
+	}
+	c.JSON(200, gin.H{"success": true, "data": stats})
+}
+
+// handleApprovals izlenen cüzdanların bilinen açık onaylarını (allowance durumu) döner
+func handleApprovals(c *gin.Context) {
+	owner := strings.TrimSpace(c.Query("owner"))
+	if owner != "" && !common.IsHexAddress(owner) {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "Geçersiz owner adresi",
+		})
+		return
+	}
+	c.JSON(200, gin.H{"success": true, "data": listener.GetAllowances(c.Query("chain"), owner)})
+}
+
+// handlePriceBreakdown token fiyatını oluşturan kaynakları, aykırı işaretleriyle döner
+func handlePriceBreakdown(c *gin.Context) {
+	token := strings.TrimSpace(c.Param("token"))
+	if !common.IsHexAddress(token) {
+		c.JSON(400, gin.H{
+			"success": false,
+			"error":   "Geçersiz token adresi",
+		})
+		return
+	}
+	bd, err := listener.GetPriceBreakdown(c.Query("chain"), token, c.Query("refresh") == "true")
+	if err != nil {
+		c.JSON(500, gin.H{
+			"success": false,
+			"error":   err.Error(),
+		})
+		return
+	}
+	c.JSON(200, gin.H{"success": true, "data": bd})
+}
@@ -0,0 +1,239 @@
+package listener
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// lookupEvent log için adrese özel ABI tanımını ya da topic sayısına uyan imza varyantını,
+// indexed düzeni çözülmüş olarak döner. Düzen kaynakta belirtilmemiş ve topic sayısından çıkarılmışsa
+// inferred true döner. Aynı topic sayısına uyan birden fazla farklı düzen varsa yanlış çözümlememek
+// için false döner.
+func lookupEvent(lg types.Log) (ev abi.Event, inferred bool, ok bool) {
+	if len(lg.Topics) == 0 {
+		return abi.Event{}, false, false
+	}
+	topic := strings.ToLower(lg.Topics[0].Hex())
+	indexed := len(lg.Topics) - 1
+	eventRegistryMu.RLock()
+	defer eventRegistryMu.RUnlock()
+	if m, ok := events.addressEvents[strings.ToLower(lg.Address.Hex())]; ok {
+		if ev, ok := m[topic]; ok {
+			return ev, false, true
+		}
+	}
+
+	// Önce düzeni bilinen varyantlar, yoksa düzeni topic sayısından çıkarılanlar
+	variants := events.globalEvents[topic]
+	for _, known := range []bool{true, false} {
+		var chosen *abi.Event
+		for _, v := range variants {
+			if v.layoutKnown != known {
+				continue
+			}
+			ev := v.ev
+			if !known {
+				var ok bool
+				if ev, ok = withInferredIndexed(ev, indexed); !ok {
+					continue
+				}
+			} else if countIndexed(ev) != indexed {
+				continue
+			}
+			if chosen != nil {
+				if !sameIndexedLayout(*chosen, ev) {
+					return abi.Event{}, false, false
+				}
+				continue
+			}
+			chosen = &ev
+		}
+		if chosen != nil {
+			return *chosen, !known, true
+		}
+	}
+	return abi.Event{}, false, false
+}
+
+func countIndexed(ev abi.Event) int {
+	n := 0
+	for _, in := range ev.Inputs {
+		if in.Indexed {
+			n++
+		}
+	}
+	return n
+}
+
+// withInferredIndexed imzadan gelen tanımda indexed parametre sayısı topic sayısından bilinir;
+// Solidity'de yaygın olduğu gibi ilk parametrelerin indexed olduğu varsayılır
+func withInferredIndexed(ev abi.Event, indexed int) (abi.Event, bool) {
+	if indexed > len(ev.Inputs) {
+		return abi.Event{}, false
+	}
+	inputs := make(abi.Arguments, len(ev.Inputs))
+	copy(inputs, ev.Inputs)
+	for i := range inputs {
+		inputs[i].Indexed = i < indexed
+	}
+	return abi.NewEvent(ev.Name, ev.RawName, ev.Anonymous, inputs), true
+}
+
+// decodedField çözümlenmiş tek event parametresi
+type decodedField struct {
+	name  string
+	value string
+	// inferred: indexed düzeni varsayıldı (imzada belirtilmemiş), değer yanlış olabilir
+	inferred bool
+}
+
+// Ondalığa göre ölçeklenecek tutar alanları (parametre adına göre)
+var amountFieldPattern = regexp.MustCompile(`(?i)amount|price|value|collateral|fee|balance|deposit|reward`)
+
+// decodeEventFields logun indexed topic'lerini ve data alanını event tanımındaki parametrelere çözer.
+// Adresler kategori/sembol etiketiyle, tutar alanları (token bilinirse) ondalığa göre ölçeklenerek döner.
+// Düzeni varsayılan (yalnızca imzası bilinen) eventlerin alanları "tahmini" olarak işaretlenir.
+func decodeEventFields(ch *chainInstance, lg types.Log) []decodedField {
+	ev, inferred, ok := lookupEvent(lg)
+	if !ok {
+		return nil
+	}
+
+	values, err := unpackEventLog(ev, lg)
+	if err != nil {
+		return nil
+	}
+	fields := formatDecodedArgs(ch, ev.Inputs, values, lg.Address)
+	if inferred {
+		for i := range fields {
+			fields[i].inferred = true
+		}
+	}
+	return fields
+}
+
+// formatDecodedArgs çözülmüş argümanları parametre sırasıyla alanlara çevirir. Tutar alanları için ondalık:
+// argümanlardaki ilk bilinen token adresi, yoksa çağrılan/logu üreten kontrat. Önce kayıtlı tokenlara
+// bakılır; hiçbiri kayıtlı değilse aynı sırayla keşfedilen metadata kullanılır (getTokenDecimals gibi).
+func formatDecodedArgs(ch *chainInstance, args abi.Arguments, values map[string]interface{}, contract common.Address) []decodedField {
+	candidates := make([]common.Address, 0, len(args)+1)
+	for _, in := range args {
+		if a, ok := values[in.Name].(common.Address); ok {
+			candidates = append(candidates, a)
+		}
+	}
+	candidates = append(candidates, contract)
+	decimals, haveDecimals := 0, false
+	for _, a := range candidates {
+		if d, ok := ch.decimals(strings.ToLower(a.Hex())); ok {
+			decimals, haveDecimals = d, true
+			break
+		}
+	}
+	if !haveDecimals {
+		for _, a := range candidates {
+			if d, ok := discoveredDecimals(ch, a); ok {
+				decimals, haveDecimals = d, true
+				break
+			}
+		}
+	}
+
+	fields := make([]decodedField, 0, len(args))
+	for i, in := range args {
+		name := in.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		v, ok := values[in.Name]
+		if !ok {
+			continue
+		}
+		fields = append(fields, decodedField{name: name, value: formatABIValue(ch, in, v, decimals, haveDecimals)})
+	}
+	return fields
+}
+
+// formatABIValue tek parametreyi okunabilir metne çevirir
+func formatABIValue(ch *chainInstance, in abi.Argument, v interface{}, decimals int, haveDecimals bool) string {
+	switch x := v.(type) {
+	case common.Address:
+		return labelAddress(ch, x)
+	case *big.Int:
+		if haveDecimals && amountFieldPattern.MatchString(in.Name) {
+			return formatTokenAmount(x, decimals)
+		}
+		return x.String()
+	case common.Hash:
+		// Indexed dinamik tiplerin (string, bytes, dizi) topic'te yalnızca hash'i bulunur
+		return x.Hex()
+	case []byte:
+		return "0x" + hex.EncodeToString(x)
+	case string:
+		return x
+	case bool:
+		return fmt.Sprintf("%v", x)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array:
+		// bytesN
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return "0x" + hex.EncodeToString(b)
+		}
+	case reflect.Slice:
+		parts := make([]string, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			parts[i] = formatABIValue(ch, abi.Argument{}, rv.Index(i).Interface(), decimals, false)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// labelAddress adresi zincirdeki kategori ya da token sembolüyle etiketler
+func labelAddress(ch *chainInstance, a common.Address) string {
+	lower := strings.ToLower(a.Hex())
+	if sym, ok := ch.symbol(lower); ok {
+		return a.Hex() + " (" + sym + ")"
+	}
+	if ch.isWatched(lower) {
+		return a.Hex() + " (" + ch.category(a) + ")"
+	}
+	return a.Hex()
+}
+
+// renderDecodedFields alanları bildirim gövdesi satırlarına çevirir (değerler kod bloğunda, escapeCodeSpan ile)
+func renderDecodedFields(fields []decodedField) string {
+	var b strings.Builder
+	for _, f := range fields {
+		b.WriteString(fmt.Sprintf("🔹 `%s`: `%s`", escapeCodeSpan(f.name), escapeCodeSpan(f.value)))
+		if f.inferred {
+			b.WriteString(" " + escapeMarkdownV2("(tahmini)"))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// compactDecodedFields alanları kod bloğuna konacak tek satırlık özet olarak döner (tx bildirimi için)
+func compactDecodedFields(fields []decodedField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.name + "=" + f.value
+		if f.inferred {
+			parts[i] += " (tahmini)"
+		}
+	}
+	return escapeCodeSpan(strings.Join(parts, ", "))
+}
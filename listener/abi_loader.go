@@ -12,9 +12,9 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 )
 
-// LoadABIs listener/abis klasöründen <address>.abi.json dosyalarını okuyup
-// event topic0 -> event adı eşleşmelerini addressTopicNames tablosuna yazar.
-func LoadABIs() error {
+// loadABIs listener/abis klasöründen <address>.abi.json dosyalarını okuyup
+// event tanımlarını (ad ve argüman çözümleme için) ve fonksiyon selector'larını kayıt defterine adrese bağlı olarak ekler.
+func (r *eventRegistry) loadABIs() error {
 	baseDir := filepath.Join("listener", "abis")
 	info, err := os.Stat(baseDir)
 	if err != nil {
@@ -60,9 +60,12 @@ func LoadABIs() error {
 			return fmt.Errorf("ABI parse hatası (%s): %w", name, err)
 		}
 
-		for evName, ev := range parsed.Events {
-			id := ev.ID // topic0 hash alanı
-			RegisterEventName(common.HexToAddress(addr), id, evName)
+		for _, ev := range parsed.Events {
+			r.addABIEvent(common.HexToAddress(addr), ev)
+		}
+		// Fonksiyon selector'ları (DiamondCut gibi selector listelerini adlandırmak için)
+		for _, m := range parsed.Methods {
+			r.addABIMethod(common.HexToAddress(addr), m)
 		}
 		return nil
 	}
@@ -0,0 +1,488 @@
+package listener
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Approval topic'leri. ERC-20 ve ERC-721 Approval aynı topic0'ı kullanır (ERC-721'de tokenId 4. topic).
+var (
+	// Approval(address indexed owner, address indexed spender, uint256 value)
+	approvalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	// ApprovalForAll(address indexed owner, address indexed operator, bool approved)
+	approvalForAllTopic = crypto.Keccak256Hash([]byte("ApprovalForAll(address,address,bool)"))
+)
+
+// Sınırsız kabul edilen allowance alt sınırı (2^255): type(uint256).max ve ondan harcanmış değerler
+var unlimitedAllowance = new(big.Int).Lsh(big.NewInt(1), 255)
+
+// Approval türleri
+const (
+	approvalERC20  = "erc20"
+	approvalERC721 = "erc721"   // tek token onayı
+	approvalForAll = "operator" // koleksiyonun tamamı (ERC-721/1155)
+)
+
+// approvalEvent çözümlenmiş Approval / ApprovalForAll logu
+type approvalEvent struct {
+	kind     string
+	owner    common.Address
+	spender  common.Address
+	value    *big.Int // erc20: allowance, erc721: tokenId
+	approved bool     // operator: verildi mi / geri alındı mı
+}
+
+// isApprovalLog logun Approval ya da ApprovalForAll olup olmadığını döner
+func isApprovalLog(lg types.Log) bool {
+	return len(lg.Topics) >= 3 && (lg.Topics[0] == approvalTopic || lg.Topics[0] == approvalForAllTopic)
+}
+
+// parseApproval Approval (ERC-20/721) ve ApprovalForAll loglarını çözer
+func parseApproval(lg types.Log) *approvalEvent {
+	if !isApprovalLog(lg) {
+		return nil
+	}
+	ev := &approvalEvent{
+		owner:   common.BytesToAddress(lg.Topics[1].Bytes()),
+		spender: common.BytesToAddress(lg.Topics[2].Bytes()),
+	}
+	switch {
+	case lg.Topics[0] == approvalForAllTopic:
+		if len(lg.Data) < 32 {
+			return nil
+		}
+		ev.kind = approvalForAll
+		ev.approved = new(big.Int).SetBytes(lg.Data[:32]).Sign() != 0
+	case len(lg.Topics) == 4:
+		ev.kind = approvalERC721
+		ev.value = lg.Topics[3].Big()
+	case len(lg.Topics) == 3:
+		if len(lg.Data) < 32 {
+			return nil
+		}
+		ev.kind = approvalERC20
+		ev.value = new(big.Int).SetBytes(lg.Data[:32])
+	default:
+		return nil
+	}
+	return ev
+}
+
+// revoked onayın geri alma (sıfır allowance / sıfır adrese onay / operator kaldırma) olup olmadığını döner
+func (a *approvalEvent) revoked() bool {
+	switch a.kind {
+	case approvalForAll:
+		return !a.approved
+	case approvalERC721:
+		return a.spender == (common.Address{})
+	}
+	return a.value.Sign() == 0
+}
+
+// unlimited ERC-20'de max uint256 civarı, operator onayında koleksiyonun tamamı
+func (a *approvalEvent) unlimited() bool {
+	switch a.kind {
+	case approvalForAll:
+		return a.approved
+	case approvalERC20:
+		return a.value.Cmp(unlimitedAllowance) >= 0
+	}
+	return false
+}
+
+// getApprovalLargeUSD büyük allowance eşiğini döner (APPROVAL_LARGE_USD, varsayılan 100000)
+func getApprovalLargeUSD() float64 {
+	if v := strings.TrimSpace(os.Getenv("APPROVAL_LARGE_USD")); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			return f
+		}
+	}
+	return 100000
+}
+
+// getApprovalLargeUnits fiyatı bilinmeyen token için büyük allowance eşiğini token birimi olarak döner
+// (APPROVAL_LARGE_UNITS, varsayılan 1000000)
+func getApprovalLargeUnits() float64 {
+	if v := strings.TrimSpace(os.Getenv("APPROVAL_LARGE_UNITS")); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			return f
+		}
+	}
+	return 1000000
+}
+
+// approvalValue ERC-20 onayının token birimi ve fiyatı biliniyorsa USD karşılığı
+type approvalValue struct {
+	units  float64
+	usd    float64
+	priced bool
+}
+
+// allowanceValue onay miktarını ölçekler ve token fiyatıyla çarpar. Transfer tutarlarındaki
+// $1M üstü güvenlik kırpması uygulanmaz: büyük onaylar tam da bu aralıkta önemlidir.
+func allowanceValue(ch *chainInstance, token common.Address, a *approvalEvent) approvalValue {
+	if a.kind != approvalERC20 || a.unlimited() || a.revoked() {
+		return approvalValue{}
+	}
+	dec := getTokenDecimals(ch, token)
+	units, _ := new(big.Float).Quo(new(big.Float).SetInt(a.value), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(dec)), nil))).Float64()
+	v := approvalValue{units: units}
+	if price := fetchTokenUSDPrice(ch, token); price > 0 {
+		v.usd = units * price
+		v.priced = true
+	}
+	return v
+}
+
+// spenderAllowed spender'ın güvenilir olup olmadığını döner: izlenen adreslerimiz, zincir tanımındaki
+// approvalAllowlist ve APPROVAL_SPENDER_ALLOWLIST (virgülle ayrılmış, tüm zincirler) listesi
+func spenderAllowed(ch *chainInstance, spender common.Address) bool {
+	lower := strings.ToLower(spender.Hex())
+	if ch.isWatched(lower) {
+		return true
+	}
+	for _, a := range ch.cfg.ApprovalAllowlist {
+		if strings.EqualFold(strings.TrimSpace(a), spender.Hex()) {
+			return true
+		}
+	}
+	for _, a := range strings.Split(os.Getenv("APPROVAL_SPENDER_ALLOWLIST"), ",") {
+		if strings.EqualFold(strings.TrimSpace(a), spender.Hex()) {
+			return true
+		}
+	}
+	return false
+}
+
+// approvalRisks onayın önemli gruba yükseltilme nedenlerini döner (yalnızca izlenen owner için)
+func approvalRisks(ch *chainInstance, a *approvalEvent, v approvalValue) []string {
+	if a.revoked() || !ch.isWatched(strings.ToLower(a.owner.Hex())) {
+		return nil
+	}
+	var risks []string
+	switch {
+	case a.unlimited():
+		risks = append(risks, "sınırsız onay")
+	case a.kind == approvalERC20 && v.priced && v.usd >= getApprovalLargeUSD():
+		risks = append(risks, fmt.Sprintf("büyük onay ~$%.0f", v.usd))
+	case a.kind == approvalERC20 && !v.priced && v.units >= getApprovalLargeUnits():
+		risks = append(risks, fmt.Sprintf("büyük onay %.0f birim (fiyat yok)", v.units))
+	}
+	if !spenderAllowed(ch, a.spender) {
+		risks = append(risks, "listede olmayan spender")
+	}
+	return risks
+}
+
+// approvalAmount onay miktarını okunur metne çevirir
+func approvalAmount(ch *chainInstance, token common.Address, a *approvalEvent) string {
+	switch a.kind {
+	case approvalForAll:
+		if a.approved {
+			return "tüm koleksiyon"
+		}
+		return "geri alındı"
+	case approvalERC721:
+		return "#" + a.value.String()
+	}
+	if a.unlimited() {
+		return "sınırsız"
+	}
+	amount := formatTokenAmount(a.value, getTokenDecimals(ch, token))
+	if sym := getAssetSymbol(ch, token); sym != "" {
+		amount += " " + sym
+	}
+	return amount
+}
+
+// formatApprovalMessage Approval / ApprovalForAll için başlık ve gövde üretir
+func formatApprovalMessage(ch *chainInstance, lg types.Log, txd txDetails) (string, string) {
+	a := parseApproval(lg)
+	if a == nil {
+		return "", ""
+	}
+
+	v := allowanceValue(ch, lg.Address, a)
+	spender := labelAddress(ch, a.spender)
+	if spenderAllowed(ch, a.spender) {
+		spender += " ✅"
+	}
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("📋 **Tx:** `%s`\n", lg.TxHash.Hex()))
+	body.WriteString(fmt.Sprintf("👤 **Owner:** `%s`\n", labelAddress(ch, a.owner)))
+	body.WriteString(fmt.Sprintf("🏦 **Token:** `%s`\n", labelAddress(ch, lg.Address)))
+	body.WriteString(fmt.Sprintf("🤝 **Spender:** `%s`\n", spender))
+	body.WriteString(fmt.Sprintf("💰 **Miktar:** `%s`\n", approvalAmount(ch, lg.Address, a)))
+	if v.usd > 0 {
+		body.WriteString(fmt.Sprintf("💵 **USD:** `~$%.2f`\n", v.usd))
+	}
+	if risks := approvalRisks(ch, a, v); len(risks) > 0 {
+		body.WriteString(fmt.Sprintf("🚨 **Risk:** `%s`\n", strings.Join(risks, ", ")))
+	}
+	body.WriteString(txd.footer())
+
+	name := "Approval"
+	if a.kind == approvalForAll {
+		name = "ApprovalForAll"
+	}
+	label := "[" + ch.category(a.owner) + "] " + name
+	emoji := "🔵"
+	if determineImportance(label, body.String()) {
+		emoji = "🔴"
+	}
+	return emoji + " " + label, body.String()
+}
+
+// approvalBundleLine tx bildirimi için tek satırlık onay özeti; risk varsa ikinci değerde döner
+func approvalBundleLine(ch *chainInstance, lg types.Log, a *approvalEvent) (string, []string) {
+	v := allowanceValue(ch, lg.Address, a)
+	line := fmt.Sprintf("▫️ `#%d Approval %s` `%s` → `%s`", lg.Index, approvalAmount(ch, lg.Address, a), shortAddr(a.owner.Hex()), shortAddr(a.spender.Hex()))
+	return line, approvalRisks(ch, a, v)
+}
+
+// AllowanceState (owner, token, spender) için bilinen son onay
+type AllowanceState struct {
+	Chain     string    `json:"chain"`
+	Owner     string    `json:"owner"`
+	Token     string    `json:"token"`
+	Symbol    string    `json:"symbol,omitempty"`
+	Spender   string    `json:"spender"`
+	Kind      string    `json:"kind"`   // erc20 | operator
+	Amount    string    `json:"amount"` // ham değer (operator için "all")
+	Formatted string    `json:"formatted"`
+	Unlimited bool      `json:"unlimited"`
+	Allowed   bool      `json:"allowed"` // spender allowlist'te mi
+	TxHash    string    `json:"txHash"`
+	Block     uint64    `json:"block"`
+	LogIndex  uint      `json:"logIndex"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var (
+	allowances       = make(map[string]AllowanceState)
+	allowanceMu      sync.Mutex
+	allowancesLoaded bool
+	// Reorg'da geri alınabilsin diye her işlenen onayın öncesindeki durum (log blockID → kayıt)
+	allowanceUndo = make(map[string]allowanceChange)
+)
+
+// allowanceChange bir onay logunun durum tablosunda yaptığı değişiklik
+type allowanceChange struct {
+	key     string
+	prev    *AllowanceState // nil: önceden kayıt yoktu
+	written *AllowanceState // nil: onay geri alımıydı, kayıt silindi
+	at      time.Time
+}
+
+// pendingApproval bildirimle birlikte kuyruğa giren, dedup sahiplenildikten sonra işlenecek onay logu
+type pendingApproval struct {
+	ch *chainInstance
+	lg types.Log
+}
+
+// approvalOf log Approval ise bildirime eklenecek onayı döner
+func approvalOf(ch *chainInstance, lg types.Log) []pendingApproval {
+	if !isApprovalLog(lg) {
+		return nil
+	}
+	return []pendingApproval{{ch: ch, lg: lg}}
+}
+
+// applyApprovals kuyruğa alınan bildirimin onaylarını durum tablosuna işler. Replay modunda kalıcı
+// durum değiştirilmez.
+func applyApprovals(list []pendingApproval) {
+	if len(list) == 0 || getEventSourceKind() == eventSourceReplay {
+		return
+	}
+	for _, pa := range list {
+		if a := parseApproval(pa.lg); a != nil {
+			trackApproval(pa.ch, pa.lg, a)
+		}
+	}
+}
+
+// getAllowanceFile allowance durum dosyasının yolunu döner (ALLOWANCE_FILE)
+func getAllowanceFile() string {
+	if v := strings.TrimSpace(os.Getenv("ALLOWANCE_FILE")); v != "" {
+		return v
+	}
+	return filepath.Join("data", "allowances.json")
+}
+
+func allowanceKey(chain string, owner, token, spender common.Address) string {
+	return strings.ToLower(chain + ":" + owner.Hex() + ":" + token.Hex() + ":" + spender.Hex())
+}
+
+// loadAllowancesLocked dosyadaki durumu bir kez belleğe alır (allowanceMu tutulmalı)
+func loadAllowancesLocked() {
+	if allowancesLoaded {
+		return
+	}
+	allowancesLoaded = true
+	b, err := os.ReadFile(getAllowanceFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Allowance dosyası okunamadı: %v", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(b, &allowances); err != nil {
+		log.Printf("⚠️ Allowance dosyası parse edilemedi: %v", err)
+	}
+}
+
+// writeAllowancesLocked durumu atomik olarak (tmp + rename) diske yazar
+func writeAllowancesLocked() error {
+	path := getAllowanceFile()
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(allowances, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// trackApproval izlenen owner'ın ERC-20 ve operator onaylarını durum tablosuna işler.
+// Daha eski bir bloktan gelen kayıt (backfill, tekrar tarama) mevcut durumu ezmez; geri almada kayıt silinir.
+// ERC-721 tek token onayları token el değiştirince sıfırlandığı için tutulmaz.
+func trackApproval(ch *chainInstance, lg types.Log, a *approvalEvent) {
+	if a.kind == approvalERC721 || !ch.isWatched(strings.ToLower(a.owner.Hex())) {
+		return
+	}
+	key := allowanceKey(ch.name(), a.owner, lg.Address, a.spender)
+	allowanceMu.Lock()
+	defer allowanceMu.Unlock()
+	loadAllowancesLocked()
+	cur, had := allowances[key]
+	if had && (cur.Block > lg.BlockNumber || (cur.Block == lg.BlockNumber && cur.LogIndex > lg.Index)) {
+		return
+	}
+	change := allowanceChange{key: key, at: time.Now()}
+	if had {
+		change.prev = &cur
+	}
+	if a.revoked() {
+		delete(allowances, key)
+	} else {
+		st := AllowanceState{
+			Chain:     ch.name(),
+			Owner:     a.owner.Hex(),
+			Token:     lg.Address.Hex(),
+			Symbol:    getAssetSymbol(ch, lg.Address),
+			Spender:   a.spender.Hex(),
+			Kind:      a.kind,
+			Amount:    "all",
+			Formatted: approvalAmount(ch, lg.Address, a),
+			Unlimited: a.unlimited(),
+			Allowed:   spenderAllowed(ch, a.spender),
+			TxHash:    lg.TxHash.Hex(),
+			Block:     lg.BlockNumber,
+			LogIndex:  lg.Index,
+			UpdatedAt: time.Now(),
+		}
+		if a.value != nil {
+			st.Amount = a.value.String()
+		}
+		allowances[key] = st
+		change.written = &st
+	}
+	pruneAllowanceUndoLocked()
+	allowanceUndo[logRefOf(ch.name(), lg).blockID()] = change
+	if err := writeAllowancesLocked(); err != nil {
+		log.Printf("⚠️ Allowance durumu yazılamadı: %v", err)
+	}
+}
+
+// undoApprovals orphan kalan loglardan gelen onayları geri alır: kayıt hâlâ o logdan geliyorsa
+// önceki durum geri yüklenir (önceden kayıt yoksa silinir)
+func undoApprovals(refs []logRef) {
+	allowanceMu.Lock()
+	defer allowanceMu.Unlock()
+	changed := false
+	for _, r := range refs {
+		id := r.blockID()
+		change, ok := allowanceUndo[id]
+		if !ok {
+			continue
+		}
+		delete(allowanceUndo, id)
+		cur, has := allowances[change.key]
+		current := !has && change.written == nil
+		if has && change.written != nil {
+			current = cur.TxHash == change.written.TxHash && cur.LogIndex == change.written.LogIndex && cur.Block == change.written.Block
+		}
+		if !current {
+			continue
+		}
+		if change.prev != nil {
+			allowances[change.key] = *change.prev
+		} else {
+			delete(allowances, change.key)
+		}
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	if err := writeAllowancesLocked(); err != nil {
+		log.Printf("⚠️ Allowance durumu yazılamadı: %v", err)
+	}
+}
+
+// pruneAllowanceUndoLocked reorg penceresini (1 saat) aşmış geri alma kayıtlarını siler (allowanceMu tutulmalı)
+func pruneAllowanceUndoLocked() {
+	cutoff := time.Now().Add(-1 * time.Hour)
+	for k, c := range allowanceUndo {
+		if c.at.Before(cutoff) {
+			delete(allowanceUndo, k)
+		}
+	}
+}
+
+// GetAllowances izlenen cüzdanların bilinen açık onaylarını döner (chain/owner boşsa süzülmez)
+func GetAllowances(chain, owner string) []AllowanceState {
+	allowanceMu.Lock()
+	defer allowanceMu.Unlock()
+	loadAllowancesLocked()
+	out := make([]AllowanceState, 0, len(allowances))
+	for _, st := range allowances {
+		if chain != "" && !strings.EqualFold(st.Chain, chain) {
+			continue
+		}
+		if owner != "" && !strings.EqualFold(st.Owner, owner) {
+			continue
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Owner != out[j].Owner {
+			return out[i].Owner < out[j].Owner
+		}
+		if out[i].Token != out[j].Token {
+			return out[i].Token < out[j].Token
+		}
+		return out[i].Spender < out[j].Spender
+	})
+	return out
+}
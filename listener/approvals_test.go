@@ -0,0 +1,125 @@
+package listener
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParseApproval(t *testing.T) {
+	spender := testOther
+	tests := []struct {
+		name     string
+		lg       types.Log
+		kind     string
+		value    int64
+		approved bool
+		revoked  bool
+		unlimit  bool
+	}{
+		{
+			name: "ERC-20 onay",
+			lg:   types.Log{Topics: []common.Hash{approvalTopic, addrTopic(testWallet), addrTopic(spender)}, Data: common.LeftPadBytes(big.NewInt(500).Bytes(), 32)},
+			kind: approvalERC20, value: 500,
+		},
+		{
+			name: "ERC-20 sıfırlama",
+			lg:   types.Log{Topics: []common.Hash{approvalTopic, addrTopic(testWallet), addrTopic(spender)}, Data: make([]byte, 32)},
+			kind: approvalERC20, revoked: true,
+		},
+		{
+			name: "ERC-20 sınırsız",
+			lg:   types.Log{Topics: []common.Hash{approvalTopic, addrTopic(testWallet), addrTopic(spender)}, Data: math.U256Bytes(new(big.Int).Set(math.MaxBig256))},
+			kind: approvalERC20, value: -1, unlimit: true,
+		},
+		{
+			name: "ERC-721 tek token",
+			lg:   types.Log{Topics: []common.Hash{approvalTopic, addrTopic(testWallet), addrTopic(spender), common.BigToHash(big.NewInt(9))}},
+			kind: approvalERC721, value: 9,
+		},
+		{
+			name: "ERC-721 onay kaldırma",
+			lg:   types.Log{Topics: []common.Hash{approvalTopic, addrTopic(testWallet), {}, common.BigToHash(big.NewInt(9))}},
+			kind: approvalERC721, value: 9, revoked: true,
+		},
+		{
+			name: "ApprovalForAll verildi",
+			lg:   types.Log{Topics: []common.Hash{approvalForAllTopic, addrTopic(testWallet), addrTopic(spender)}, Data: common.LeftPadBytes([]byte{1}, 32)},
+			kind: approvalForAll, approved: true, unlimit: true,
+		},
+		{
+			name: "ApprovalForAll geri alındı",
+			lg:   types.Log{Topics: []common.Hash{approvalForAllTopic, addrTopic(testWallet), addrTopic(spender)}, Data: make([]byte, 32)},
+			kind: approvalForAll, revoked: true,
+		},
+		{name: "eksik data", lg: types.Log{Topics: []common.Hash{approvalTopic, addrTopic(testWallet), addrTopic(spender)}}},
+		{name: "Transfer logu", lg: types.Log{Topics: []common.Hash{transferTopic, addrTopic(testWallet), addrTopic(spender)}, Data: make([]byte, 32)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := parseApproval(tt.lg)
+			if tt.kind == "" {
+				if a != nil {
+					t.Fatalf("nil bekleniyordu, %+v döndü", a)
+				}
+				return
+			}
+			if a == nil {
+				t.Fatal("onay çözülemedi")
+			}
+			if a.kind != tt.kind || a.owner != testWallet {
+				t.Errorf("tür=%s owner=%s", a.kind, a.owner.Hex())
+			}
+			if tt.value > 0 && a.value.Int64() != tt.value {
+				t.Errorf("değer=%v, beklenen %d", a.value, tt.value)
+			}
+			if a.approved != tt.approved || a.revoked() != tt.revoked || a.unlimited() != tt.unlimit {
+				t.Errorf("approved=%v revoked=%v unlimited=%v", a.approved, a.revoked(), a.unlimited())
+			}
+		})
+	}
+}
+
+func TestApprovalRisks(t *testing.T) {
+	t.Setenv("APPROVAL_SPENDER_ALLOWLIST", testOther.Hex())
+	t.Setenv("APPROVAL_LARGE_USD", "1000")
+	t.Setenv("APPROVAL_LARGE_UNITS", "5000")
+	ch := testChain()
+	unknown := common.HexToAddress("0x00000000000000000000000000000000000000e9")
+	erc20 := func(owner, spender common.Address, value *big.Int) *approvalEvent {
+		return &approvalEvent{kind: approvalERC20, owner: owner, spender: spender, value: value}
+	}
+
+	tests := []struct {
+		name string
+		a    *approvalEvent
+		v    approvalValue
+		want []string
+	}{
+		{"küçük onay", erc20(testWallet, testOther, big.NewInt(10)), approvalValue{units: 10, usd: 10, priced: true}, nil},
+		{"büyük onay", erc20(testWallet, testOther, big.NewInt(2000)), approvalValue{units: 2000, usd: 2500, priced: true}, []string{"büyük onay ~$2500"}},
+		// Fiyat biliniyorsa birim eşiği kullanılmaz
+		{"fiyatlı çok birim", erc20(testWallet, testOther, big.NewInt(9000)), approvalValue{units: 9000, usd: 90, priced: true}, nil},
+		{"fiyatsız büyük onay", erc20(testWallet, testOther, big.NewInt(9000)), approvalValue{units: 9000}, []string{"büyük onay 9000 birim (fiyat yok)"}},
+		{"fiyatsız küçük onay", erc20(testWallet, testOther, big.NewInt(10)), approvalValue{units: 10}, nil},
+		{"sınırsız", erc20(testWallet, testOther, new(big.Int).Set(unlimitedAllowance)), approvalValue{}, []string{"sınırsız onay"}},
+		{"listede olmayan spender", erc20(testWallet, unknown, big.NewInt(1)), approvalValue{units: 1, usd: 1, priced: true}, []string{"listede olmayan spender"}},
+		{"sınırsız ve bilinmeyen spender", erc20(testWallet, unknown, new(big.Int).Set(unlimitedAllowance)), approvalValue{}, []string{"sınırsız onay", "listede olmayan spender"}},
+		// İzlenen spender güvenilir sayılır
+		{"izlenen spender", erc20(unknown, testWallet, big.NewInt(1)), approvalValue{}, nil},
+		{"izlenmeyen owner", erc20(unknown, unknown, new(big.Int).Set(unlimitedAllowance)), approvalValue{}, nil},
+		{"geri alma", erc20(testWallet, unknown, big.NewInt(0)), approvalValue{}, nil},
+		{"operator onayı", &approvalEvent{kind: approvalForAll, owner: testWallet, spender: testOther, approved: true}, approvalValue{}, []string{"sınırsız onay"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := approvalRisks(ch, tt.a, tt.v); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%q, beklenen %q", got, tt.want)
+			}
+		})
+	}
+}
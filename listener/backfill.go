@@ -0,0 +1,348 @@
+package listener
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// BackfillOptions geçmiş blok aralığını bildirim göndermeden indekslemek için parametreler.
+// Blok ya da zaman sınırı verilebilir; zaman verilirse en yakın blok ikili aramayla bulunur.
+type BackfillOptions struct {
+	Chain     string // boşsa varsayılan zincir
+	FromBlock uint64
+	ToBlock   uint64 // 0 ve ToTime boşsa head
+	FromTime  time.Time
+	ToTime    time.Time
+	Output    string // boşsa data/backfill-<zincir>-<from>-<to>.jsonl
+}
+
+// backfillRecord çıktı dosyasındaki tek satır
+type backfillRecord struct {
+	ID        string    `json:"id"`
+	Chain     string    `json:"chain"`
+	Kind      string    `json:"kind"` // log | native | internal
+	Block     uint64    `json:"block"`
+	BlockHash string    `json:"blockHash"`
+	TxHash    string    `json:"txHash"`
+	LogIndex  *uint     `json:"logIndex,omitempty"`
+	Important bool      `json:"important"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	IndexedAt time.Time `json:"indexedAt"`
+}
+
+// backfillProgress çıktı dosyasının yanındaki (<out>.progress) devam bilgisi.
+// Canlı checkpoint dosyası başka bir süreç tarafından yazılıyor olabileceği için kullanılmaz.
+type backfillProgress struct {
+	Chain     string    `json:"chain"`
+	From      uint64    `json:"from"`
+	To        uint64    `json:"to"`
+	Block     uint64    `json:"block"` // tamamen yazılmış son blok
+	Records   int       `json:"records"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// backfillWriter pipeline'ın ürettiği eventleri çözümleyip çıktı dosyasına yazar
+type backfillWriter struct {
+	path     string
+	f        *os.File
+	w        *bufio.Writer
+	enc      *json.Encoder
+	seen     map[string]bool // devam ederken yarım kalmış aralığın kayıtları tekrar yazılmasın
+	progress backfillProgress
+}
+
+func openBackfillWriter(path string, progress backfillProgress) (*backfillWriter, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	bw := &backfillWriter{path: path, seen: make(map[string]bool), progress: progress}
+
+	// Mevcut kayıtlar: yalnızca kimlikleri okunur
+	if f, err := os.Open(path); err == nil {
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for sc.Scan() {
+			var rec struct {
+				ID string `json:"id"`
+			}
+			if json.Unmarshal(sc.Bytes(), &rec) == nil && rec.ID != "" {
+				bw.seen[rec.ID] = true
+			}
+		}
+		f.Close()
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	bw.f = f
+	bw.w = bufio.NewWriter(f)
+	bw.enc = json.NewEncoder(bw.w)
+	bw.progress.Records = len(bw.seen)
+	return bw, nil
+}
+
+// write eventi canlı akışla aynı çözümleme ve fiyat hesaplamasından geçirip dosyaya ekler
+func (bw *backfillWriter) write(ctx context.Context, p *blockPipeline, ev pipelineEvent) error {
+	rec := backfillRecord{Chain: p.chain.name(), Block: ev.blockNumber, BlockHash: ev.blockHash.Hex()}
+	var item notificationItem
+	if ev.native != nil {
+		ref := p.nativeRef(*ev.native, ev.blockNumber, ev.blockHash)
+		if bw.seen[ref.id()] {
+			return nil
+		}
+		item = p.nativeNotification(ctx, *ev.native, ref)
+		rec.Kind = "native"
+		if ev.native.internal {
+			rec.Kind = "internal"
+		}
+		rec.TxHash = ev.native.txHash.Hex()
+	} else {
+		lg := *ev.lg
+		if lg.Removed || !isRelevantLog(p.chain, lg) || bw.seen[logRefOf(p.chain.name(), lg).id()] {
+			return nil
+		}
+		var ok bool
+		if item, ok = p.logNotification(ctx, lg); !ok {
+			return nil
+		}
+		idx := lg.Index
+		rec.Kind = "log"
+		rec.TxHash = lg.TxHash.Hex()
+		rec.LogIndex = &idx
+	}
+
+	rec.ID = item.refs[0].id()
+	rec.Title = item.title
+	rec.Body = item.body
+	rec.Important = determineImportance(item.title, item.body)
+	rec.IndexedAt = time.Now()
+	if err := bw.enc.Encode(rec); err != nil {
+		return err
+	}
+	bw.seen[rec.ID] = true
+	bw.progress.Records++
+	return nil
+}
+
+// commit aralığın kayıtlarını diske yazar ve ardından devam noktasını günceller
+func (bw *backfillWriter) commit(block uint64) error {
+	if err := bw.w.Flush(); err != nil {
+		return err
+	}
+	if err := bw.f.Sync(); err != nil {
+		return err
+	}
+	bw.progress.Block = block
+	bw.progress.UpdatedAt = time.Now()
+	b, err := json.MarshalIndent(bw.progress, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := bw.path + ".progress.tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, bw.path+".progress")
+}
+
+func (bw *backfillWriter) close() {
+	_ = bw.w.Flush()
+	_ = bw.f.Close()
+}
+
+// readBackfillProgress aynı çıktı için önceki çalıştırmanın devam noktasını okur
+func readBackfillProgress(path string) (backfillProgress, bool) {
+	b, err := os.ReadFile(path + ".progress")
+	if err != nil {
+		return backfillProgress{}, false
+	}
+	var pr backfillProgress
+	if err := json.Unmarshal(b, &pr); err != nil {
+		log.Printf("⚠️ Backfill devam dosyası parse edilemedi: %v", err)
+		return backfillProgress{}, false
+	}
+	return pr, true
+}
+
+// blockAtTime zamanı t'den küçük olmayan ilk bloğu (after=false) ya da zamanı t'yi geçmeyen
+// son bloğu (after=true) ikili aramayla bulur
+func blockAtTime(ctx context.Context, client *ethclient.Client, t time.Time, head uint64, last bool) (uint64, error) {
+	target := uint64(t.Unix())
+	timeOf := func(bn uint64) (uint64, error) {
+		hdr, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(bn))
+		if err != nil {
+			return 0, err
+		}
+		return hdr.Time, nil
+	}
+	lo, hi := uint64(0), head
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		if last {
+			mid = lo + (hi-lo+1)/2
+		}
+		ts, err := timeOf(mid)
+		if err != nil {
+			return 0, err
+		}
+		if last {
+			if ts <= target {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		} else {
+			if ts >= target {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+	}
+	return lo, nil
+}
+
+// RunBackfill geçmiş blok aralığını canlı pipeline'ın çözümleme ve fiyat adımlarıyla işler,
+// sonuçları Telegram yerine JSONL dosyasına yazar. Aynı çıktıyla tekrar çalıştırıldığında
+// kaldığı bloktan devam eder. Canlı dedup, reorg ve checkpoint durumuna dokunmaz.
+func RunBackfill(ctx context.Context, opts BackfillOptions) error {
+	ch, err := lookupChain(opts.Chain)
+	if err != nil {
+		return err
+	}
+	if len(ch.cfg.RPC) == 0 {
+		return fmt.Errorf("[%s] RPC tanımlı değil", ch.label())
+	}
+
+	if _, err := ReloadSignatures(); err != nil {
+		log.Printf("⚠️ İmza yükleme hatası: %v", err)
+	}
+
+	pool := newRPCPool(ch.name(), ch.cfg.RPC, ch.cfg.RPC[0], ch.cfg.HTTPRPC)
+	pool.startHealthChecks(ctx)
+	ch.setPool(pool)
+	defer func() {
+		ch.setPool(nil)
+		pool.close()
+	}()
+
+	client, err := pool.client()
+	if err != nil {
+		return err
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	from, to := opts.FromBlock, opts.ToBlock
+	if !opts.FromTime.IsZero() {
+		if from, err = blockAtTime(ctx, client, opts.FromTime, head, false); err != nil {
+			return fmt.Errorf("başlangıç bloğu bulunamadı: %w", err)
+		}
+	}
+	if !opts.ToTime.IsZero() {
+		if to, err = blockAtTime(ctx, client, opts.ToTime, head, true); err != nil {
+			return fmt.Errorf("bitiş bloğu bulunamadı: %w", err)
+		}
+	}
+	if to == 0 || to > head {
+		to = head
+	}
+	if from == 0 || from > to {
+		return fmt.Errorf("geçersiz aralık: %d-%d (head=%d)", from, to, head)
+	}
+
+	out := opts.Output
+	if out == "" {
+		out = filepath.Join("data", fmt.Sprintf("backfill-%s-%d-%d.jsonl", ch.name(), from, to))
+	}
+
+	start := from
+	progress := backfillProgress{Chain: ch.name(), From: from, To: to}
+	if pr, ok := readBackfillProgress(out); ok && pr.Chain == ch.name() && pr.From == from && pr.To == to && pr.Block >= from {
+		start = pr.Block + 1
+		progress = pr
+		log.Printf("⏩ [%s] Backfill kaldığı yerden devam ediyor: %d (%d kayıt yazılmış)", ch.label(), start, pr.Records)
+	}
+
+	bw, err := openBackfillWriter(out, progress)
+	if err != nil {
+		return err
+	}
+	defer bw.close()
+
+	p := &blockPipeline{chain: ch, src: newRPCSource(ch, pool), backfill: bw, cursor: start - 1}
+	total := to - from + 1
+	maxRange := getPipelineMaxRange()
+	began := time.Now()
+	lastReport := time.Now()
+	failures := 0
+
+	log.Printf("📚 [%s] Backfill başlatıldı: %d → %d (%d blok), çıktı=%s", ch.label(), start, to, to-start+1, out)
+	for p.cursor < to {
+		if ctx.Err() != nil {
+			log.Printf("🛑 [%s] Backfill durduruldu (blok=%d), aynı komutla devam edilebilir", ch.label(), p.cursor)
+			return ctx.Err()
+		}
+		// Her aralıkta en sağlıklı sağlayıcı seçilir
+		if _, err := p.src.BlockNumber(ctx); err != nil {
+			failures++
+		} else {
+			rangeFrom := p.cursor + 1
+			rangeTo := rangeFrom + maxRange - 1
+			if rangeTo > to {
+				rangeTo = to
+			}
+			err = p.processRange(ctx, rangeFrom, rangeTo)
+			switch {
+			case err == nil:
+				failures = 0
+			case err == errRangeReorg:
+				continue
+			default:
+				p.src.ReportFailure(err)
+				failures++
+			}
+		}
+		if failures > 0 {
+			if failures >= 10 {
+				return fmt.Errorf("backfill blok %d'de üst üste %d kez başarısız oldu, aynı komutla devam edilebilir", p.cursor+1, failures)
+			}
+			log.Printf("⚠️ [%s] Backfill aralığı başarısız (blok=%d, deneme=%d), tekrar denenecek", ch.label(), p.cursor+1, failures)
+			select {
+			case <-ctx.Done():
+			case <-time.After(3 * time.Second):
+			}
+			continue
+		}
+
+		if time.Since(lastReport) >= 10*time.Second || p.cursor >= to {
+			lastReport = time.Now()
+			done := p.cursor - from + 1
+			pct := float64(done) * 100 / float64(total)
+			eta := ""
+			if processed := p.cursor - start + 1; processed > 0 && p.cursor < to {
+				rate := float64(processed) / time.Since(began).Seconds()
+				eta = fmt.Sprintf(", kalan ~%s", (time.Duration(float64(to-p.cursor)/rate) * time.Second).Round(time.Second))
+			}
+			log.Printf("📈 [%s] Backfill: %d/%d blok (%%%.1f), %d kayıt%s", ch.label(), done, total, pct, bw.progress.Records, eta)
+		}
+	}
+	log.Printf("✅ [%s] Backfill tamamlandı: %d-%d, %d kayıt → %s (%s)", ch.label(), from, to, bw.progress.Records, out, time.Since(began).Round(time.Second))
+	return nil
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
-	"os"
+	"sort"
 	"strings"
 
 	"github.com/ethereum/go-ethereum"
@@ -30,6 +30,7 @@ var HubContracts = map[string]common.Address{
 // BalanceResponse balance sorgusu için response
 type BalanceResponse struct {
 	Success bool   `json:"success"`
+	Chain   string `json:"chain"`
 	Token   string `json:"token"`
 	Address string `json:"address"`
 	Balance string `json:"balance"`
@@ -37,39 +38,115 @@ type BalanceResponse struct {
 	Error   string `json:"error,omitempty"`
 }
 
-// GetTokenBalance belirtilen token'ın balance'ını döner
-func GetTokenBalance(token string) (*BalanceResponse, error) {
-	rpcUrl := os.Getenv("ARBITRUM_RPC")
-	if rpcUrl == "" {
-		return nil, fmt.Errorf("ARBITRUM_RPC ortam değişkeni tanımlı değil")
+// balanceContracts zincirin bakiye sorgularında kullanılan token ve hub kontratlarını döner.
+// Varsayılan zincir TokenContracts/HubContracts, diğerleri CHAINS_FILE içindeki balanceTokens/hubs kullanır.
+func balanceContracts(ch *chainInstance) (map[string]common.Address, map[string]common.Address) {
+	if ch.global {
+		return TokenContracts, HubContracts
 	}
+	tokens := make(map[string]common.Address, len(ch.cfg.BalanceTokens))
+	for sym, addr := range ch.cfg.BalanceTokens {
+		tokens[strings.ToUpper(sym)] = common.HexToAddress(addr)
+	}
+	hubs := make(map[string]common.Address, len(ch.cfg.Hubs))
+	for name, addr := range ch.cfg.Hubs {
+		if !strings.EqualFold(name, "Main") {
+			name = strings.ToUpper(name)
+		} else {
+			name = "Main"
+		}
+		hubs[name] = common.HexToAddress(addr)
+	}
+	return tokens, hubs
+}
 
-	client, err := ethclient.Dial(rpcUrl)
+// SupportedBalanceTokens zincirde bakiye sorgusu desteklenen token sembollerini döner
+func SupportedBalanceTokens(chain string) []string {
+	ch, err := lookupChain(chain)
+	if err != nil {
+		return nil
+	}
+	tokens, _ := balanceContracts(ch)
+	out := make([]string, 0, len(tokens))
+	for sym := range tokens {
+		out = append(out, sym)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// dialBalanceClient zincirin birincil RPC'sine bakiye sorguları için bağlanır
+func dialBalanceClient(ch *chainInstance) (*ethclient.Client, error) {
+	if len(ch.cfg.RPC) == 0 {
+		if ch.global {
+			return nil, fmt.Errorf("ARBITRUM_RPC ortam değişkeni tanımlı değil")
+		}
+		return nil, fmt.Errorf("%s zinciri için RPC tanımlı değil", ch.name())
+	}
+	client, err := ethclient.Dial(ch.cfg.RPC[0])
 	if err != nil {
 		return nil, fmt.Errorf("RPC bağlantısı kurulamadı: %v", err)
 	}
+	return client, nil
+}
+
+// balanceDecimals token'ın ondalık sayısını döner (zincir token kaydı → keşfedilen metadata → bilinen semboller)
+func balanceDecimals(ctx context.Context, ch *chainInstance, client *ethclient.Client, symbol string, tokenAddr common.Address) int {
+	if d, ok := ch.decimals(strings.ToLower(tokenAddr.Hex())); ok {
+		return d
+	}
+	m, ok := cachedTokenMeta(ch, tokenAddr)
+	if !ok && tokenDiscoveryEnabled() {
+		m, _ = discoverTokenMeta(ctx, ch, client, tokenAddr)
+	}
+	if m.Decimals != nil {
+		return *m.Decimals
+	}
+	switch symbol {
+	case "USDT", "USDC":
+		return 6
+	case "WBTC":
+		return 8
+	default:
+		return 18
+	}
+}
+
+// GetTokenBalance belirtilen zincirde (boşsa varsayılan zincir) token hub'ının balance'ını döner
+func GetTokenBalance(chain, token string) (*BalanceResponse, error) {
+	ch, err := lookupChain(chain)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dialBalanceClient(ch)
+	if err != nil {
+		return nil, err
+	}
 	defer client.Close()
 
 	ctx := context.Background()
+	tokenContracts, hubContracts := balanceContracts(ch)
 
 	// Token adını büyük harfe çevir
 	tokenUpper := strings.ToUpper(token)
 
 	// Hub kontratını bul
-	hubAddr, exists := HubContracts[tokenUpper]
+	hubAddr, exists := hubContracts[tokenUpper]
 	if !exists {
 		return &BalanceResponse{
 			Success: false,
+			Chain:   ch.name(),
 			Token:   token,
 			Error:   fmt.Sprintf("Desteklenmeyen token: %s", token),
 		}, nil
 	}
 
 	// Token kontratını bul
-	tokenAddr, exists := TokenContracts[tokenUpper]
+	tokenAddr, exists := tokenContracts[tokenUpper]
 	if !exists {
 		return &BalanceResponse{
 			Success: false,
+			Chain:   ch.name(),
 			Token:   token,
 			Error:   fmt.Sprintf("Token kontratı bulunamadı: %s", token),
 		}, nil
@@ -89,6 +166,7 @@ func GetTokenBalance(token string) (*BalanceResponse, error) {
 	if err != nil {
 		return &BalanceResponse{
 			Success: false,
+			Chain:   ch.name(),
 			Token:   token,
 			Error:   fmt.Sprintf("Kontrat çağrısı hatası: %v", err),
 		}, nil
@@ -97,6 +175,7 @@ func GetTokenBalance(token string) (*BalanceResponse, error) {
 	if len(result) < 32 {
 		return &BalanceResponse{
 			Success: false,
+			Chain:   ch.name(),
 			Token:   token,
 			Error:   "Geçersiz response uzunluğu",
 		}, nil
@@ -105,23 +184,14 @@ func GetTokenBalance(token string) (*BalanceResponse, error) {
 	balance := new(big.Int).SetBytes(result)
 
 	// Decimal'ları ayarla
-	var decimals int
-	switch tokenUpper {
-	case "USDT":
-		decimals = 6
-	case "ETH":
-		decimals = 18
-	case "WBTC":
-		decimals = 8
-	default:
-		decimals = 18
-	}
+	decimals := balanceDecimals(ctx, ch, client, tokenUpper, tokenAddr)
 
 	// Balance'ı formatla
 	balanceStr := formatBalance(balance, decimals)
 
 	return &BalanceResponse{
 		Success: true,
+		Chain:   ch.name(),
 		Token:   tokenUpper,
 		Address: hubAddr.Hex(),
 		Balance: balanceStr,
@@ -129,26 +199,31 @@ func GetTokenBalance(token string) (*BalanceResponse, error) {
 	}, nil
 }
 
-// GetMainBalance ana kontratın ETH balance'ını döner
-func GetMainBalance() (*BalanceResponse, error) {
-	rpcUrl := os.Getenv("ARBITRUM_RPC")
-	if rpcUrl == "" {
-		return nil, fmt.Errorf("ARBITRUM_RPC ortam değişkeni tanımlı değil")
+// GetMainBalance ana kontratın native coin balance'ını döner
+func GetMainBalance(chain string) (*BalanceResponse, error) {
+	ch, err := lookupChain(chain)
+	if err != nil {
+		return nil, err
+	}
+	_, hubContracts := balanceContracts(ch)
+	mainAddr, ok := hubContracts["Main"]
+	if !ok {
+		return &BalanceResponse{Success: false, Chain: ch.name(), Token: "Main", Error: "Main kontratı tanımlı değil"}, nil
 	}
 
-	client, err := ethclient.Dial(rpcUrl)
+	client, err := dialBalanceClient(ch)
 	if err != nil {
-		return nil, fmt.Errorf("RPC bağlantısı kurulamadı: %v", err)
+		return nil, err
 	}
 	defer client.Close()
 
 	ctx := context.Background()
-	mainAddr := HubContracts["Main"]
 
 	balance, err := client.BalanceAt(ctx, mainAddr, nil)
 	if err != nil {
 		return &BalanceResponse{
 			Success: false,
+			Chain:   ch.name(),
 			Token:   "Main",
 			Error:   fmt.Sprintf("Balance sorgusu hatası: %v", err),
 		}, nil
@@ -158,43 +233,48 @@ func GetMainBalance() (*BalanceResponse, error) {
 
 	return &BalanceResponse{
 		Success: true,
+		Chain:   ch.name(),
 		Token:   "Main",
 		Address: mainAddr.Hex(),
 		Balance: balanceStr,
-		Symbol:  "ETH",
+		Symbol:  ch.cfg.NativeSymbol,
 	}, nil
 }
 
 // GetMainTokenBalance ana kontratın belirtilen token balance'ını döner
-func GetMainTokenBalance(token string) (*BalanceResponse, error) {
-	rpcUrl := os.Getenv("ARBITRUM_RPC")
-	if rpcUrl == "" {
-		return nil, fmt.Errorf("ARBITRUM_RPC ortam değişkeni tanımlı değil")
+func GetMainTokenBalance(chain, token string) (*BalanceResponse, error) {
+	ch, err := lookupChain(chain)
+	if err != nil {
+		return nil, err
 	}
-
-	client, err := ethclient.Dial(rpcUrl)
+	client, err := dialBalanceClient(ch)
 	if err != nil {
-		return nil, fmt.Errorf("RPC bağlantısı kurulamadı: %v", err)
+		return nil, err
 	}
 	defer client.Close()
 
 	ctx := context.Background()
+	tokenContracts, hubContracts := balanceContracts(ch)
 
 	// Token adını büyük harfe çevir
 	tokenUpper := strings.ToUpper(token)
 
 	// Token kontratını bul
-	tokenAddr, exists := TokenContracts[tokenUpper]
+	tokenAddr, exists := tokenContracts[tokenUpper]
 	if !exists {
 		return &BalanceResponse{
 			Success: false,
+			Chain:   ch.name(),
 			Token:   token,
 			Error:   fmt.Sprintf("Token kontratı bulunamadı: %s", token),
 		}, nil
 	}
 
 	// Main kontrat adresi
-	mainAddr := HubContracts["Main"]
+	mainAddr, ok := hubContracts["Main"]
+	if !ok {
+		return &BalanceResponse{Success: false, Chain: ch.name(), Token: token, Error: "Main kontratı tanımlı değil"}, nil
+	}
 
 	// ERC20 balanceOf çağrısı
 	data := []byte{0x70, 0xa0, 0x82, 0x31}   // balanceOf(address) function selector
@@ -210,6 +290,7 @@ func GetMainTokenBalance(token string) (*BalanceResponse, error) {
 	if err != nil {
 		return &BalanceResponse{
 			Success: false,
+			Chain:   ch.name(),
 			Token:   token,
 			Error:   fmt.Sprintf("Kontrat çağrısı hatası: %v", err),
 		}, nil
@@ -218,6 +299,7 @@ func GetMainTokenBalance(token string) (*BalanceResponse, error) {
 	if len(result) < 32 {
 		return &BalanceResponse{
 			Success: false,
+			Chain:   ch.name(),
 			Token:   token,
 			Error:   "Geçersiz response uzunluğu",
 		}, nil
@@ -226,23 +308,14 @@ func GetMainTokenBalance(token string) (*BalanceResponse, error) {
 	balance := new(big.Int).SetBytes(result)
 
 	// Decimal'ları ayarla
-	var decimals int
-	switch tokenUpper {
-	case "USDT":
-		decimals = 6
-	case "ETH":
-		decimals = 18
-	case "WBTC":
-		decimals = 8
-	default:
-		decimals = 18
-	}
+	decimals := balanceDecimals(ctx, ch, client, tokenUpper, tokenAddr)
 
 	// Balance'ı formatla
 	balanceStr := formatBalance(balance, decimals)
 
 	return &BalanceResponse{
 		Success: true,
+		Chain:   ch.name(),
 		Token:   "Main_" + tokenUpper,
 		Address: mainAddr.Hex(),
 		Balance: balanceStr,
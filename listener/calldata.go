@@ -0,0 +1,93 @@
+package listener
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// txCall izlenen adresi ilgilendiren (izlenen kontrata giden ya da izlenen adresten çıkan) tx'in çağrı verisi
+type txCall struct {
+	from  string // küçük harf
+	to    string // küçük harf, kontrat oluşturmada boş
+	input []byte
+}
+
+// decodedCall tx input'unun çözümlenmiş hali
+type decodedCall struct {
+	selector   string
+	sig        string   // çözülemediyse boş
+	candidates []string // selector çakışmasında aday imzalar (argümanlar çözülmez)
+	fields     []decodedField
+}
+
+// callTouchesWatched tx'in izlenen bir adrese gidip gitmediğini ya da izlenen adresten çıkıp çıkmadığını döner
+func callTouchesWatched(ch *chainInstance, from, to string) bool {
+	return (to != "" && ch.isWatched(to)) || ch.isWatched(from)
+}
+
+// decodeCalldata tx input'unu çağrılan kontratın ABI'si, diğer ABI'ler ve yerel selector veritabanıyla çözer.
+// Input 4 byte'tan kısaysa (düz transfer) nil döner.
+func decodeCalldata(ch *chainInstance, call txCall) *decodedCall {
+	if len(call.input) < 4 || call.to == "" {
+		return nil
+	}
+	var sel [4]byte
+	copy(sel[:], call.input[:4])
+	dc := &decodedCall{selector: "0x" + hex.EncodeToString(sel[:])}
+	to := common.HexToAddress(call.to)
+	m, candidates, ok := lookupMethod(sel, to)
+	if !ok {
+		dc.candidates = candidates
+		return dc
+	}
+	dc.sig = m.Sig
+	if len(m.Inputs) == 0 {
+		return dc
+	}
+	values := make(map[string]interface{}, len(m.Inputs))
+	if err := m.Inputs.UnpackIntoMap(values, call.input[4:]); err != nil {
+		// İmza tutuyor ama argümanlar uymuyor (ör. selector çakışması): yalnızca ad gösterilir
+		return dc
+	}
+	dc.fields = formatDecodedArgs(ch, m.Inputs, values, to)
+	return dc
+}
+
+// label çağrının tek satırlık adı (imza, çakışmada adaylar, bilinmiyorsa selector)
+func (dc *decodedCall) label() string {
+	switch {
+	case dc.sig != "":
+		return dc.sig
+	case len(dc.candidates) > 0:
+		return dc.selector + " ? " + strings.Join(dc.candidates, " | ")
+	}
+	return dc.selector + " bilinmeyen"
+}
+
+// renderCall çağrıyı bildirim gövdesi satırlarına çevirir (değerler kod bloğunda)
+func renderCall(dc *decodedCall) string {
+	if dc == nil {
+		return ""
+	}
+	return fmt.Sprintf("📞 **Çağrı:** `%s`\n", escapeCodeSpan(dc.label())) + renderDecodedFields(dc.fields)
+}
+
+// callLines tx'in çağrı bilgisini (pipeline aralığında toplanmışsa) bildirim satırları olarak döner
+func (p *blockPipeline) callLines(txHash common.Hash) string {
+	call, ok := p.calls[txHash]
+	if !ok {
+		return ""
+	}
+	return renderCall(decodeCalldata(p.chain, call))
+}
+
+// withCall bildirim gövdesinin sonuna tx çağrı bilgisini ekler
+func (p *blockPipeline) withCall(body string, txHash common.Hash) string {
+	if lines := p.callLines(txHash); lines != "" {
+		return body + "\n" + strings.TrimSuffix(lines, "\n")
+	}
+	return body
+}
@@ -0,0 +1,364 @@
+package listener
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// chainToken zincirin token kaydındaki tek token
+type chainToken struct {
+	Symbol   string `json:"symbol"`
+	Decimals int    `json:"decimals"`
+}
+
+// chainConfig CHAINS_FILE içindeki tek zincir tanımı
+type chainConfig struct {
+	Name              string                `json:"name"`
+	Label             string                `json:"label"`
+	ChainID           uint64                `json:"chainId"`
+	RPC               []string              `json:"rpc"`     // ${ENV} ifadeleri açılır (API anahtarları dosyada tutulmasın)
+	HTTPRPC           string                `json:"httpRpc"` // ilk RPC için raw HTTP adresi (opsiyonel)
+	CoinGeckoPlatform string                `json:"coingeckoPlatform"`
+	DexScreenerChain  string                `json:"dexscreenerChain"` // boşsa DexScreener sonuçları zincire göre süzülmez
+	NativeSymbol      string                `json:"nativeSymbol"`
+	NativeUSDPrice    float64               `json:"nativeUsdPrice"`
+	WrappedNative     string                `json:"wrappedNative"`     // native fiyatı bu token üzerinden çekilir
+	WalletProfile     string                `json:"walletProfile"`     // prod | test (wallets boşsa)
+	Wallets           map[string]string     `json:"wallets"`           // adres -> kategori etiketi
+	Tokens            map[string]chainToken `json:"tokens"`            // token adresi -> sembol/ondalık
+	BalanceTokens     map[string]string     `json:"balanceTokens"`     // sembol -> token adresi (API)
+	Hubs              map[string]string     `json:"hubs"`              // sembol ya da "Main" -> hub adresi (API)
+	ApprovalAllowlist []string              `json:"approvalAllowlist"` // güvenilir spender adresleri (approval uyarıları)
+	PriceFeeds        map[string]string     `json:"priceFeeds"`        // token adresi -> Chainlink USD feed adresi
+	StaticPrices      map[string]float64    `json:"staticPrices"`      // token adresi -> sabit USD fiyatı
+}
+
+// chainInstance çalışan tek zincir örneği. Varsayılan zincir (global=true) mevcut global
+// izleme listesini, token kayıtlarını ve bakiye kontratlarını kullanır.
+type chainInstance struct {
+	cfg    chainConfig
+	global bool
+
+	watchAddresses []common.Address
+	watchMap       map[string]bool
+	categories     map[string]string
+	tokenSymbols   map[string]string
+	tokenDecimals  map[string]int
+
+	// Zincir çalışırken açık sağlayıcı havuzu; API handler'ları da okuduğu için atomik tutulur
+	pool atomic.Pointer[rpcPool]
+}
+
+// activePool zincirin açık sağlayıcı havuzunu döner (zincir çalışmıyorsa ya da havuz kapandıysa nil)
+func (c *chainInstance) activePool() *rpcPool {
+	return c.pool.Load()
+}
+
+// setPool havuzu zincire bağlar; nil havuzu kapanmadan önce ayırır
+func (c *chainInstance) setPool(p *rpcPool) {
+	c.pool.Store(p)
+}
+
+var (
+	chainsOnce  sync.Once
+	chainList   []*chainInstance
+	chainByName = make(map[string]*chainInstance)
+)
+
+// getChainsFile ek zincir tanımlarının dosyasını döner (CHAINS_FILE, varsayılan chains.json)
+func getChainsFile() string {
+	if v := strings.TrimSpace(os.Getenv("CHAINS_FILE")); v != "" {
+		return v
+	}
+	return "chains.json"
+}
+
+// defaultChainConfig mevcut ARBITRUM_* ortam değişkenlerinden varsayılan zinciri oluşturur
+func defaultChainConfig() chainConfig {
+	name := getChainName()
+	label := strings.TrimSpace(os.Getenv("CHAIN_LABEL"))
+	if label == "" {
+		label = strings.ToUpper(name[:1]) + name[1:]
+	}
+	return chainConfig{
+		Name:              name,
+		Label:             label,
+		RPC:               getRPCURLs(),
+		HTTPRPC:           strings.TrimSpace(os.Getenv("ARBITRUM_HTTP_RPC")),
+		CoinGeckoPlatform: "arbitrum-one",
+		NativeSymbol:      "ETH",
+	}
+}
+
+// loadChains varsayılan zinciri ve CHAINS_FILE içindeki ek zincirleri bir kez yükler.
+// CHAINS (virgülle ayrılmış) tanımlıysa yalnızca listelenen zincirler etkinleştirilir.
+func loadChains() {
+	chainsOnce.Do(func() {
+		var enabled map[string]bool
+		if v := strings.TrimSpace(os.Getenv("CHAINS")); v != "" {
+			enabled = make(map[string]bool)
+			for _, n := range strings.Split(v, ",") {
+				if n = strings.ToLower(strings.TrimSpace(n)); n != "" {
+					enabled[n] = true
+				}
+			}
+		}
+
+		def := defaultChainConfig()
+		if enabled == nil || enabled[def.Name] {
+			registerChain(&chainInstance{cfg: def, global: true})
+		}
+
+		b, err := os.ReadFile(getChainsFile())
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("⚠️ Zincir dosyası okunamadı: %v", err)
+			}
+			return
+		}
+		var cfgs []chainConfig
+		if err := json.Unmarshal(b, &cfgs); err != nil {
+			log.Printf("⚠️ Zincir dosyası parse edilemedi: %v", err)
+			return
+		}
+		for _, cfg := range cfgs {
+			cfg.Name = strings.ToLower(strings.TrimSpace(cfg.Name))
+			if cfg.Name == "" {
+				log.Printf("⚠️ İsimsiz zincir tanımı atlandı")
+				continue
+			}
+			if cfg.Name == def.Name {
+				log.Printf("⚠️ %s varsayılan zincir, ARBITRUM_* ortam değişkenleriyle yapılandırılır; dosyadaki tanım atlandı", cfg.Name)
+				continue
+			}
+			if enabled != nil && !enabled[cfg.Name] {
+				continue
+			}
+			if _, dup := chainByName[cfg.Name]; dup {
+				log.Printf("⚠️ Tekrarlanan zincir tanımı atlandı: %s", cfg.Name)
+				continue
+			}
+			registerChain(newChainInstance(cfg))
+		}
+	})
+}
+
+func registerChain(ch *chainInstance) {
+	chainList = append(chainList, ch)
+	chainByName[ch.name()] = ch
+	log.Printf("⛓️ Zincir yüklendi: %s (%d izlenen adres, %d RPC)", ch.label(), len(ch.addresses()), len(ch.cfg.RPC))
+}
+
+// newChainInstance dosyadan gelen tanım için izleme listesini ve token kayıtlarını hazırlar
+func newChainInstance(cfg chainConfig) *chainInstance {
+	if cfg.Label == "" {
+		cfg.Label = strings.ToUpper(cfg.Name[:1]) + cfg.Name[1:]
+	}
+	if cfg.NativeSymbol == "" {
+		cfg.NativeSymbol = "ETH"
+	}
+	var rpcs []string
+	for _, u := range cfg.RPC {
+		if u = strings.TrimSpace(os.ExpandEnv(u)); u != "" {
+			rpcs = append(rpcs, u)
+		}
+	}
+	cfg.RPC = rpcs
+	cfg.HTTPRPC = strings.TrimSpace(os.ExpandEnv(cfg.HTTPRPC))
+
+	ch := &chainInstance{
+		cfg:           cfg,
+		watchMap:      make(map[string]bool),
+		categories:    make(map[string]string),
+		tokenSymbols:  make(map[string]string),
+		tokenDecimals: make(map[string]int),
+	}
+
+	// İzleme listesi: açık cüzdan listesi → profil → varsayılan zincirle aynı adresler
+	addWallet := func(addr, label string) {
+		a := common.HexToAddress(addr)
+		lower := strings.ToLower(a.Hex())
+		if !ch.watchMap[lower] {
+			ch.watchAddresses = append(ch.watchAddresses, a)
+		}
+		ch.watchMap[lower] = true
+		ch.categories[lower] = label
+	}
+	switch {
+	case len(cfg.Wallets) > 0:
+		addrs := make([]string, 0, len(cfg.Wallets))
+		for a := range cfg.Wallets {
+			addrs = append(addrs, a)
+		}
+		sort.Strings(addrs)
+		for _, a := range addrs {
+			addWallet(a, cfg.Wallets[a])
+		}
+	case cfg.WalletProfile != "":
+		for _, w := range walletsForProfile(cfg.WalletProfile) {
+			addWallet(w.addr, w.label)
+		}
+	default:
+		for _, a := range WatchAddresses {
+			addWallet(a.Hex(), GetAddressCategory(a))
+		}
+	}
+
+	for addr, t := range cfg.Tokens {
+		lower := strings.ToLower(common.HexToAddress(addr).Hex())
+		if t.Symbol != "" {
+			ch.tokenSymbols[lower] = t.Symbol
+		}
+		if t.Decimals > 0 {
+			ch.tokenDecimals[lower] = t.Decimals
+		}
+	}
+	return ch
+}
+
+// activeChains etkin zincirleri yükleme sırasıyla döner
+func activeChains() []*chainInstance {
+	loadChains()
+	return chainList
+}
+
+// defaultChain varsayılan (ilk yüklenen) zinciri döner
+func defaultChain() *chainInstance {
+	if list := activeChains(); len(list) > 0 {
+		return list[0]
+	}
+	return &chainInstance{cfg: defaultChainConfig(), global: true}
+}
+
+// lookupChain isme göre zinciri döner; isim boşsa varsayılan zincir
+func lookupChain(name string) (*chainInstance, error) {
+	loadChains()
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return defaultChain(), nil
+	}
+	if ch, ok := chainByName[name]; ok {
+		return ch, nil
+	}
+	return nil, fmt.Errorf("bilinmeyen zincir: %s", name)
+}
+
+func (c *chainInstance) name() string  { return c.cfg.Name }
+func (c *chainInstance) label() string { return c.cfg.Label }
+
+// addresses zincirde izlenen adresleri döner
+func (c *chainInstance) addresses() []common.Address {
+	if c.global {
+		return WatchAddresses
+	}
+	return c.watchAddresses
+}
+
+// isWatched küçük harfli adresin bu zincirde izlenip izlenmediğini döner
+func (c *chainInstance) isWatched(lower string) bool {
+	if c.global {
+		return WatchMap[lower]
+	}
+	return c.watchMap[lower]
+}
+
+// category adresin bu zincirdeki kategori etiketini döner
+func (c *chainInstance) category(addr common.Address) string {
+	if !c.global {
+		if v, ok := c.categories[strings.ToLower(addr.Hex())]; ok {
+			return v
+		}
+	}
+	return GetCategoryLabel(addr)
+}
+
+// symbol token adresinin (küçük harf) bu zincirdeki sembolünü döner
+func (c *chainInstance) symbol(lower string) (string, bool) {
+	if c.global {
+		s, ok := tokenSymbols[lower]
+		return s, ok
+	}
+	s, ok := c.tokenSymbols[lower]
+	return s, ok
+}
+
+// decimals token adresinin (küçük harf) bu zincirdeki ondalık sayısını döner
+func (c *chainInstance) decimals(lower string) (int, bool) {
+	if c.global {
+		d, ok := tokenDecimals[lower]
+		return d, ok
+	}
+	d, ok := c.tokenDecimals[lower]
+	return d, ok
+}
+
+// titled bildirim başlığına emojiden sonra zincir etiketini ekler: "🔵 [Arbitrum] [USDT] Transfer"
+func (c *chainInstance) titled(title string) string {
+	parts := strings.SplitN(title, " ", 2)
+	if len(parts) != 2 {
+		return "[" + c.label() + "] " + title
+	}
+	return parts[0] + " [" + c.label() + "] " + parts[1]
+}
+
+// nativeUSDPrice zincirin native coin fiyatını döner: wrapped token fiyatı → sabit fiyat → env (varsayılan zincir)
+func (c *chainInstance) nativeUSDPrice() float64 {
+	if c.cfg.WrappedNative != "" {
+		if p := fetchTokenUSDPrice(c, common.HexToAddress(c.cfg.WrappedNative)); p > 0 {
+			return p
+		}
+	}
+	if c.cfg.NativeUSDPrice > 0 {
+		return c.cfg.NativeUSDPrice
+	}
+	if c.global {
+		return getNativeUSDPrice()
+	}
+	return 0
+}
+
+// ChainInfo API için zincir özeti
+type ChainInfo struct {
+	Name          string `json:"name"`
+	Label         string `json:"label"`
+	ChainID       uint64 `json:"chainId,omitempty"`
+	NativeSymbol  string `json:"nativeSymbol"`
+	Watched       int    `json:"watchedAddresses"`
+	Head          uint64 `json:"head"`
+	LastProcessed uint64 `json:"lastProcessedBlock"`
+	Default       bool   `json:"default"`
+}
+
+// GetChains etkin zincirlerin özetini döner
+func GetChains() []ChainInfo {
+	var out []ChainInfo
+	for _, ch := range activeChains() {
+		info := ChainInfo{
+			Name:         ch.name(),
+			Label:        ch.label(),
+			ChainID:      ch.cfg.ChainID,
+			NativeSymbol: ch.cfg.NativeSymbol,
+			Watched:      len(ch.addresses()),
+			Head:         headOf(ch.name()),
+			Default:      ch.global,
+		}
+		if cp, ok := getCheckpoint(chainCheckpointName(ch.name())); ok {
+			info.LastProcessed = cp.Block
+		}
+		out = append(out, info)
+	}
+	return out
+}
+
+// DefaultChainName varsayılan zincirin adını döner
+func DefaultChainName() string {
+	return defaultChain().name()
+}
@@ -0,0 +1,135 @@
+package listener
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Pipeline isimleri (checkpoint anahtarları)
+const (
+	pipelineBlocks = "blocks"
+
+	// Ayrı watcher'lar döneminden kalan checkpoint'ler (yalnızca geçiş için okunur)
+	legacyPipelineLogs          = "logs"
+	legacyPipelineTransfersFrom = "transfers_from"
+	legacyPipelineTransfersTo   = "transfers_to"
+	legacyPipelineNative        = "native"
+)
+
+// chainCheckpointName zincirin blok pipeline'ı için checkpoint anahtarını döner.
+// Varsayılan zincir geri uyumluluk için önek kullanmaz.
+func chainCheckpointName(chain string) string {
+	if chain == "" || chain == getChainName() {
+		return pipelineBlocks
+	}
+	return chain + ":" + pipelineBlocks
+}
+
+// blockCheckpoint bir pipeline'ın tamamen işlediği son blok
+type blockCheckpoint struct {
+	Block     uint64    `json:"block"`
+	Hash      string    `json:"hash"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var (
+	checkpoints      = make(map[string]blockCheckpoint)
+	checkpointMu     sync.Mutex
+	checkpointLoaded bool
+)
+
+// getCheckpointFile checkpoint dosyasının yolunu döner (CHECKPOINT_FILE)
+func getCheckpointFile() string {
+	if v := strings.TrimSpace(os.Getenv("CHECKPOINT_FILE")); v != "" {
+		return v
+	}
+	return filepath.Join("data", "checkpoints.json")
+}
+
+// loadCheckpointsLocked dosyadaki checkpoint'leri bir kez belleğe alır (checkpointMu tutulmalı)
+func loadCheckpointsLocked() {
+	if checkpointLoaded {
+		return
+	}
+	checkpointLoaded = true
+
+	b, err := os.ReadFile(getCheckpointFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Checkpoint dosyası okunamadı: %v", err)
+		}
+		return
+	}
+	var stored map[string]blockCheckpoint
+	if err := json.Unmarshal(b, &stored); err != nil {
+		log.Printf("⚠️ Checkpoint dosyası parse edilemedi: %v", err)
+		return
+	}
+	for name, cp := range stored {
+		checkpoints[name] = cp
+	}
+	log.Printf("📌 %d pipeline checkpoint'i yüklendi (%s)", len(stored), getCheckpointFile())
+}
+
+// getCheckpoint pipeline için kayıtlı son işlenmiş bloğu döner
+func getCheckpoint(name string) (blockCheckpoint, bool) {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+	loadCheckpointsLocked()
+	cp, ok := checkpoints[name]
+	return cp, ok
+}
+
+// saveCheckpoint pipeline'ın son tamamen işlenmiş bloğunu diske yazar.
+// Geriye gidiş (daha küçük blok) kabul edilmez.
+func saveCheckpoint(name string, block uint64, hash common.Hash) {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+	loadCheckpointsLocked()
+
+	if cur, ok := checkpoints[name]; ok && cur.Block > block {
+		return
+	}
+	checkpoints[name] = blockCheckpoint{Block: block, Hash: hash.Hex(), UpdatedAt: time.Now()}
+	if err := writeCheckpointsLocked(); err != nil {
+		log.Printf("⚠️ Checkpoint yazılamadı (%s=%d): %v", name, block, err)
+	}
+}
+
+// rewindCheckpoint checkpoint'i (reorg sonrası) zorla geri sarar
+func rewindCheckpoint(name string, block uint64) {
+	checkpointMu.Lock()
+	defer checkpointMu.Unlock()
+	loadCheckpointsLocked()
+
+	checkpoints[name] = blockCheckpoint{Block: block, UpdatedAt: time.Now()}
+	if err := writeCheckpointsLocked(); err != nil {
+		log.Printf("⚠️ Checkpoint geri sarılamadı (%s=%d): %v", name, block, err)
+	}
+}
+
+// writeCheckpointsLocked tüm checkpoint'leri atomik olarak (tmp + rename) diske yazar
+func writeCheckpointsLocked() error {
+	path := getCheckpointFile()
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(checkpoints, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
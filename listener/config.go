@@ -11,3 +11,11 @@ func IsEventEnabled(eventName string) bool {
 	key := "EVENT_" + strings.ToUpper(eventName)
 	return strings.ToLower(os.Getenv(key)) == "true"
 }
+
+// getChainName izlenen zincirin adını döner (CHAIN_NAME, varsayılan "arbitrum")
+func getChainName() string {
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("CHAIN_NAME"))); v != "" {
+		return v
+	}
+	return "arbitrum"
+}
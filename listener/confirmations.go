@@ -0,0 +1,413 @@
+package listener
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Onay modları
+const (
+	confirmModeHold = "hold" // bildirim N blok derinliğe ulaşana kadar bekletilir
+	confirmModeEdit = "edit" // hemen "onaysız" gönderilir, derinliğe ulaşınca mesaj "onaylandı" olarak düzenlenir
+)
+
+// Zincir başına pipeline'ların gördüğü en güncel head
+var (
+	chainHeadsMu sync.Mutex
+	chainHeads   = make(map[string]uint64)
+)
+
+// observeHead zincirin pipeline'ından gelen head bilgisini kaydeder
+func observeHead(chain string, n uint64) {
+	chainHeadsMu.Lock()
+	defer chainHeadsMu.Unlock()
+	if n > chainHeads[chain] {
+		chainHeads[chain] = n
+	}
+}
+
+// headOf zincirin bilinen en güncel head'ini döner (henüz yoksa 0)
+func headOf(chain string) uint64 {
+	chainHeadsMu.Lock()
+	defer chainHeadsMu.Unlock()
+	return chainHeads[chain]
+}
+
+// itemChain bildirimin ait olduğu zinciri döner
+func itemChain(item notificationItem) string {
+	if len(item.refs) > 0 {
+		return item.refs[0].chain
+	}
+	return ""
+}
+
+// pendingConfirmation onay derinliği bekleyen bildirim
+type pendingConfirmation struct {
+	item  notificationItem
+	chain string
+	block uint64
+	depth uint64
+}
+
+// unconfirmedMessage "edit" modunda onaysız gönderilmiş ve düzenlenmeyi bekleyen bildirim
+type unconfirmedMessage struct {
+	chain  string
+	refs   []logRef
+	sent   sentRef
+	marker string
+	block  uint64
+	depth  uint64
+}
+
+var (
+	confirmMu          sync.Mutex
+	pendingConfirms    []pendingConfirmation
+	pendingLoaded      bool
+	unconfirmedSent    []unconfirmedMessage
+	unconfirmedTexts   = make(map[[2]int]string) // (chat, message) -> son gönderilen metin
+	confirmModeWarned  bool
+	confirmDepthLogged bool
+)
+
+// getPendingConfirmsFile "hold" modunda bekletilen bildirimlerin yazıldığı dosyayı döner (PENDING_CONFIRMS_FILE).
+// Bekleyen bildirimlerin kayıtları dedup'ta işaretli ve checkpoint bloklarını geçmiş olduğundan
+// süreç beklenmedik şekilde sonlansa da yeniden başlatmada bu dosyadan geri yüklenirler.
+func getPendingConfirmsFile() string {
+	if v := strings.TrimSpace(os.Getenv("PENDING_CONFIRMS_FILE")); v != "" {
+		return v
+	}
+	return filepath.Join("data", "pending_confirms.json")
+}
+
+// storedRef dosyadaki kayıt biçimi
+type storedRef struct {
+	Chain string `json:"chain"`
+	Key   string `json:"key"`
+	Block uint64 `json:"block"`
+	Hash  string `json:"hash"`
+}
+
+// storedPendingConfirm dosyadaki bekleyen bildirim
+type storedPendingConfirm struct {
+	Title string      `json:"title"`
+	Body  string      `json:"body"`
+	Time  time.Time   `json:"time"`
+	Refs  []storedRef `json:"refs"`
+	Chain string      `json:"chain"`
+	Block uint64      `json:"block"`
+	Depth uint64      `json:"depth"`
+}
+
+// loadPendingLocked bekleyen bildirimleri dosyadan bir kez geri yükler (confirmMu tutulmalı).
+// Replay kalıcı dosyayı kullanmaz.
+func loadPendingLocked() {
+	if pendingLoaded {
+		return
+	}
+	pendingLoaded = true
+	if getEventSourceKind() == eventSourceReplay {
+		return
+	}
+	b, err := os.ReadFile(getPendingConfirmsFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Bekleyen onay dosyası okunamadı: %v", err)
+		}
+		return
+	}
+	var stored []storedPendingConfirm
+	if err := json.Unmarshal(b, &stored); err != nil {
+		log.Printf("⚠️ Bekleyen onay dosyası parse edilemedi: %v", err)
+		return
+	}
+	for _, s := range stored {
+		item := notificationItem{title: s.Title, body: s.Body, time: s.Time}
+		for _, r := range s.Refs {
+			item.refs = append(item.refs, logRef{chain: r.Chain, key: r.Key, blockNumber: r.Block, blockHash: common.HexToHash(r.Hash)})
+		}
+		// Reorg izleyicisi geri yüklenen kayıtları da kontrol etsin
+		trackRefs(item.refs)
+		pendingConfirms = append(pendingConfirms, pendingConfirmation{item: item, chain: s.Chain, block: s.Block, depth: s.Depth})
+	}
+	if len(stored) > 0 {
+		log.Printf("📌 %d onay bekleyen bildirim geri yüklendi (%s)", len(stored), getPendingConfirmsFile())
+	}
+}
+
+// writePendingLocked bekleyen bildirimleri atomik olarak (tmp + rename) diske yazar (confirmMu tutulmalı)
+func writePendingLocked() {
+	if getEventSourceKind() == eventSourceReplay {
+		return
+	}
+	stored := make([]storedPendingConfirm, 0, len(pendingConfirms))
+	for _, p := range pendingConfirms {
+		s := storedPendingConfirm{Title: p.item.title, Body: p.item.body, Time: p.item.time, Chain: p.chain, Block: p.block, Depth: p.depth}
+		for _, r := range p.item.refs {
+			s.Refs = append(s.Refs, storedRef{Chain: r.chain, Key: r.key, Block: r.blockNumber, Hash: r.blockHash.Hex()})
+		}
+		stored = append(stored, s)
+	}
+	err := func() error {
+		path := getPendingConfirmsFile()
+		if dir := filepath.Dir(path); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+		b, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		tmp := path + ".tmp"
+		if err := os.WriteFile(tmp, b, 0644); err != nil {
+			return err
+		}
+		return os.Rename(tmp, path)
+	}()
+	if err != nil {
+		log.Printf("⚠️ Bekleyen onaylar yazılamadı: %v", err)
+	}
+}
+
+// getConfirmationMode CONFIRMATION_MODE değerini döner (hold | edit)
+func getConfirmationMode() string {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("CONFIRMATION_MODE")))
+	switch mode {
+	case "", confirmModeHold:
+		return confirmModeHold
+	case confirmModeEdit:
+		return confirmModeEdit
+	}
+	if !confirmModeWarned {
+		confirmModeWarned = true
+		log.Printf("⚠️ Geçersiz CONFIRMATION_MODE=%q, 'hold' kullanılıyor", mode)
+	}
+	return confirmModeHold
+}
+
+func parseConfirmationEnv(key string) uint64 {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// requiredConfirmations bildirimin önem derecesine göre gereken blok derinliğini döner.
+// CONFIRMATIONS_IMPORTANT / CONFIRMATIONS_NORMAL tanımlı değilse CONFIRMATIONS kullanılır.
+func requiredConfirmations(item notificationItem) uint64 {
+	return confirmationsFor(determineImportance(item.title, item.body))
+}
+
+// confirmationsFor önemli ya da normal bildirimler için gereken blok derinliğini döner
+func confirmationsFor(important bool) uint64 {
+	base := parseConfirmationEnv("CONFIRMATIONS")
+	importantDepth := base
+	normal := base
+	if os.Getenv("CONFIRMATIONS_IMPORTANT") != "" {
+		importantDepth = parseConfirmationEnv("CONFIRMATIONS_IMPORTANT")
+	}
+	if os.Getenv("CONFIRMATIONS_NORMAL") != "" {
+		normal = parseConfirmationEnv("CONFIRMATIONS_NORMAL")
+	}
+	if importantDepth == 0 && normal == 0 {
+		return 0
+	}
+	if !confirmDepthLogged {
+		confirmDepthLogged = true
+		log.Printf("⏳ Onay derinliği aktif: önemli=%d, normal=%d blok (mod=%s)", importantDepth, normal, getConfirmationMode())
+	}
+	if important {
+		return importantDepth
+	}
+	return normal
+}
+
+// itemBlock bildirimin dayandığı en yeni blok numarasını döner
+func itemBlock(item notificationItem) uint64 {
+	var bn uint64
+	for _, r := range item.refs {
+		if r.blockNumber > bn {
+			bn = r.blockNumber
+		}
+	}
+	return bn
+}
+
+// confirmationMarker "edit" modunda bildirim gövdesine eklenen, daha sonra değiştirilecek satır
+func confirmationMarker(item notificationItem, depth uint64) string {
+	id := ""
+	if len(item.refs) > 0 {
+		id = strings.SplitN(item.refs[0].key, ":", 2)[0]
+		if len(id) > 10 {
+			id = id[:10]
+		}
+	}
+	return "⏳ **Onay:** `" + id + " bekleniyor (" + strconv.FormatUint(depth, 10) + " blok)`"
+}
+
+// admitForConfirmation buffer'dan alınan bildirimi onay politikasına göre işler.
+// "hold" modunda bildirim bekletilir ve false döner; aksi halde (gerekirse işaretlenmiş) bildirim döner.
+func admitForConfirmation(item notificationItem) (notificationItem, bool) {
+	depth := requiredConfirmations(item)
+	block := itemBlock(item)
+	if depth == 0 || block == 0 {
+		return item, true
+	}
+	if head := headOf(itemChain(item)); head >= block+depth {
+		return item, true
+	}
+
+	if getConfirmationMode() == confirmModeEdit {
+		item.confirmDepth = depth
+		item.confirmMarker = confirmationMarker(item, depth)
+		item.body = item.body + "\n" + item.confirmMarker
+		return item, true
+	}
+
+	confirmMu.Lock()
+	loadPendingLocked()
+	pendingConfirms = append(pendingConfirms, pendingConfirmation{item: item, chain: itemChain(item), block: block, depth: depth})
+	writePendingLocked()
+	confirmMu.Unlock()
+	if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+		log.Printf("⏳ Bildirim onay bekliyor (blok=%d, derinlik=%d): %s", block, depth, item.title)
+	}
+	return item, false
+}
+
+// releaseConfirmed yeterli derinliğe ulaşmış bekleyen bildirimleri döner. Dosyaya bekleyen listenin
+// çıkarılmış hali yazılır; bildirimin gönderimi bundan sonra gerçekleşir.
+func releaseConfirmed() []notificationItem {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	loadPendingLocked()
+
+	var ready []notificationItem
+	remaining := pendingConfirms[:0]
+	for _, p := range pendingConfirms {
+		if head := headOf(p.chain); head > 0 && head >= p.block+p.depth {
+			ready = append(ready, p.item)
+			continue
+		}
+		remaining = append(remaining, p)
+	}
+	pendingConfirms = remaining
+	if len(ready) > 0 {
+		writePendingLocked()
+	}
+	return ready
+}
+
+// drainPendingConfirms kapanışta onay bekleyen bildirimleri ele alır. Bekleyenler dosyada kaldığından
+// yeniden başlatmada geri yüklenip derinliğe ulaşınca gönderilir; kalıcı dosya kullanılmayan replay'de
+// ise derinlik beklenmeden gönderilmek üzere döner.
+func drainPendingConfirms() []notificationItem {
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	loadPendingLocked()
+	if getEventSourceKind() != eventSourceReplay {
+		if len(pendingConfirms) > 0 {
+			writePendingLocked()
+			log.Printf("⏳ Kapanış: %d onay bekleyen bildirim yeniden başlatmada gönderilmek üzere saklandı", len(pendingConfirms))
+		}
+		return nil
+	}
+	var out []notificationItem
+	for _, p := range pendingConfirms {
+		it := p.item
+		it.body = it.body + "\n⏳ **Onay:** `kapanış nedeniyle " + strconv.FormatUint(p.depth, 10) + " blok beklenmeden gönderildi`"
+		out = append(out, it)
+	}
+	pendingConfirms = nil
+	return out
+}
+
+// trackUnconfirmed "edit" modunda gönderilen onaysız bildirimi düzenleme için kaydeder
+func trackUnconfirmed(item notificationItem, sent sentRef) {
+	if item.confirmDepth == 0 || sent.messageID == 0 {
+		return
+	}
+	confirmMu.Lock()
+	defer confirmMu.Unlock()
+	unconfirmedSent = append(unconfirmedSent, unconfirmedMessage{
+		chain:  itemChain(item),
+		refs:   item.refs,
+		sent:   sent,
+		marker: item.confirmMarker,
+		block:  itemBlock(item),
+		depth:  item.confirmDepth,
+	})
+	key := [2]int{sent.chatID, sent.messageID}
+	if _, ok := unconfirmedTexts[key]; !ok {
+		unconfirmedTexts[key] = sent.text
+	}
+}
+
+// confirmSentMessages derinliğe ulaşmış onaysız mesajları "onaylandı" olarak düzenler.
+// Orphan kalanlar düzenlenmez (retraction ayrıca gönderilir).
+func confirmSentMessages() {
+	bot := getBotInstance()
+
+	confirmMu.Lock()
+	var ready []unconfirmedMessage
+	remaining := unconfirmedSent[:0]
+	for _, u := range unconfirmedSent {
+		if head := headOf(u.chain); head > 0 && head >= u.block+u.depth {
+			ready = append(ready, u)
+			continue
+		}
+		remaining = append(remaining, u)
+	}
+	unconfirmedSent = remaining
+	confirmMu.Unlock()
+
+	for _, u := range ready {
+		orphan := false
+		for _, r := range u.refs {
+			if isOrphaned(r) {
+				orphan = true
+				break
+			}
+		}
+		key := [2]int{u.sent.chatID, u.sent.messageID}
+
+		confirmMu.Lock()
+		text := unconfirmedTexts[key]
+		if !orphan {
+			confirmed := "✅ **Onay:** `" + strconv.FormatUint(u.depth, 10) + " blok derinlikte onaylandı`"
+			text = strings.Replace(text, u.marker, confirmed, 1)
+			unconfirmedTexts[key] = text
+		}
+		// Aynı mesaja bağlı başka onaysız kayıt kalmadıysa metni bırak
+		stillPending := false
+		for _, other := range unconfirmedSent {
+			if other.sent.chatID == key[0] && other.sent.messageID == key[1] {
+				stillPending = true
+				break
+			}
+		}
+		if !stillPending {
+			delete(unconfirmedTexts, key)
+		}
+		confirmMu.Unlock()
+
+		if orphan || bot == nil {
+			continue
+		}
+		if err := bot.EditMessageText(u.sent.chatID, u.sent.messageID, text); err != nil {
+			log.Printf("❌ Onay düzenlemesi başarısız (chat=%d, msg=%d): %v", u.sent.chatID, u.sent.messageID, err)
+		} else if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+			log.Printf("✅ Bildirim onaylandı olarak düzenlendi (chat=%d, msg=%d)", u.sent.chatID, u.sent.messageID)
+		}
+	}
+}
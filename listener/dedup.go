@@ -0,0 +1,226 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dedupEntry kalıcı dosyada tutulan tek kayıt
+type dedupEntry struct {
+	Key  string    `json:"k"`
+	Seen time.Time `json:"t"`
+}
+
+// dedupStore (chain, txHash, logIndex) anahtarlı, boyutu sınırlı ve diske yazılan tekrar önleme deposu.
+// En eski kayıtlar (ekleme sırasına göre) kapasite aşıldığında atılır.
+type dedupStore struct {
+	mu      sync.Mutex
+	loaded  bool
+	dirty   bool
+	memory  bool // yalnızca bellekte: dosyadan okunmaz, diske yazılmaz (replay)
+	entries map[string]time.Time
+	order   []string
+}
+
+var eventDedup = &dedupStore{entries: make(map[string]time.Time)}
+
+// newMemoryDedup canlı dedup dosyasına dokunmayan, boş başlayan bir depo döner (replay)
+func newMemoryDedup() *dedupStore {
+	return &dedupStore{entries: make(map[string]time.Time), loaded: true, memory: true}
+}
+
+// getDedupFile dedup dosyasının yolunu döner (DEDUP_FILE)
+func getDedupFile() string {
+	if v := strings.TrimSpace(os.Getenv("DEDUP_FILE")); v != "" {
+		return v
+	}
+	return filepath.Join("data", "dedup.json")
+}
+
+// getDedupMaxEntries depoda tutulacak en fazla kayıt sayısını döner (DEDUP_MAX_ENTRIES)
+func getDedupMaxEntries() int {
+	if v := strings.TrimSpace(os.Getenv("DEDUP_MAX_ENTRIES")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 50000
+}
+
+// dedupKey kayıt için zincir önekli dedup anahtarını üretir
+func dedupKey(r logRef) string {
+	return r.id()
+}
+
+func (d *dedupStore) loadLocked() {
+	if d.loaded {
+		return
+	}
+	d.loaded = true
+
+	b, err := os.ReadFile(getDedupFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Dedup dosyası okunamadı: %v", err)
+		}
+		return
+	}
+	var stored []dedupEntry
+	if err := json.Unmarshal(b, &stored); err != nil {
+		log.Printf("⚠️ Dedup dosyası parse edilemedi: %v", err)
+		return
+	}
+	for _, e := range stored {
+		if _, ok := d.entries[e.Key]; ok {
+			continue
+		}
+		d.entries[e.Key] = e.Seen
+		d.order = append(d.order, e.Key)
+	}
+	d.evictLocked()
+	log.Printf("📌 %d dedup kaydı yüklendi (%s)", len(d.entries), getDedupFile())
+}
+
+// evictLocked kapasiteyi aşan en eski kayıtları atar
+func (d *dedupStore) evictLocked() {
+	max := getDedupMaxEntries()
+	for len(d.order) > max {
+		oldest := d.order[0]
+		d.order = d.order[1:]
+		delete(d.entries, oldest)
+		d.dirty = true
+	}
+}
+
+// claim kayıtların hepsi daha önce görüldüyse false döner; aksi halde hepsini işaretler
+func (d *dedupStore) claim(refs []logRef) bool {
+	if len(refs) == 0 {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loadLocked()
+
+	fresh := false
+	for _, r := range refs {
+		if _, ok := d.entries[dedupKey(r)]; !ok {
+			fresh = true
+			break
+		}
+	}
+	if !fresh {
+		return false
+	}
+	now := time.Now()
+	for _, r := range refs {
+		k := dedupKey(r)
+		if _, ok := d.entries[k]; ok {
+			continue
+		}
+		d.entries[k] = now
+		d.order = append(d.order, k)
+	}
+	d.dirty = true
+	d.evictLocked()
+	return true
+}
+
+// seenAll kayıtların hepsi daha önce işlendiyse true döner (işaretlemeden)
+func (d *dedupStore) seenAll(refs []logRef) bool {
+	if len(refs) == 0 {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loadLocked()
+	for _, r := range refs {
+		if _, ok := d.entries[dedupKey(r)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// forget kayıtları depodan siler (reorg sonrası kanonik zincirdeki karşılığı tekrar bildirilebilsin)
+func (d *dedupStore) forget(refs []logRef) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.loadLocked()
+	removed := false
+	for _, r := range refs {
+		k := dedupKey(r)
+		if _, ok := d.entries[k]; ok {
+			delete(d.entries, k)
+			removed = true
+		}
+	}
+	if !removed {
+		return
+	}
+	order := d.order[:0]
+	for _, k := range d.order {
+		if _, ok := d.entries[k]; ok {
+			order = append(order, k)
+		}
+	}
+	d.order = order
+	d.dirty = true
+}
+
+// flush değişiklik varsa depoyu atomik olarak diske yazar
+func (d *dedupStore) flush() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.dirty || d.memory {
+		return nil
+	}
+	stored := make([]dedupEntry, 0, len(d.order))
+	for _, k := range d.order {
+		stored = append(stored, dedupEntry{Key: k, Seen: d.entries[k]})
+	}
+	b, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	path := getDedupFile()
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	d.dirty = false
+	return nil
+}
+
+// startDedupPersister dedup deposunu ctx iptal edilene kadar periyodik olarak diske yazar
+// (son yazma Shutdown içinde yapılır)
+func startDedupPersister(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := eventDedup.flush(); err != nil {
+				log.Printf("⚠️ Dedup deposu yazılamadı: %v", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,167 @@
+package listener
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DiamondCut((address,uint8,bytes4[])[],address,bytes) (EIP-2535); parametrelerin hiçbiri indexed değil
+var diamondCutTopic = crypto.Keccak256Hash([]byte("DiamondCut((address,uint8,bytes4[])[],address,bytes)"))
+
+var diamondCutArgs = func() abi.Arguments {
+	cutType, _ := abi.NewType("tuple[]", "", []abi.ArgumentMarshaling{
+		{Name: "facetAddress", Type: "address"},
+		{Name: "action", Type: "uint8"},
+		{Name: "functionSelectors", Type: "bytes4[]"},
+	})
+	addrType, _ := abi.NewType("address", "", nil)
+	bytesType, _ := abi.NewType("bytes", "", nil)
+	return abi.Arguments{{Name: "_diamondCut", Type: cutType}, {Name: "_init", Type: addrType}, {Name: "_calldata", Type: bytesType}}
+}()
+
+// facetCut tek facet değişikliği (IDiamondCut.FacetCut)
+type facetCut struct {
+	FacetAddress      common.Address
+	Action            uint8
+	FunctionSelectors [][4]byte
+}
+
+// diamondCut çözümlenmiş DiamondCut eventi
+type diamondCut struct {
+	cuts     []facetCut
+	init     common.Address
+	calldata []byte
+}
+
+// decodeDiamondCut DiamondCut logunun data alanını çözer
+func decodeDiamondCut(lg types.Log) (*diamondCut, error) {
+	out, err := diamondCutArgs.Unpack(lg.Data)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) != 3 {
+		return nil, fmt.Errorf("beklenmeyen DiamondCut argüman sayısı: %d", len(out))
+	}
+	// Unpack anonim struct dilimi döner; alan adları facetCut ile aynı
+	cuts := *abi.ConvertType(out[0], new([]facetCut)).(*[]facetCut)
+	init, _ := out[1].(common.Address)
+	calldata, _ := out[2].([]byte)
+	return &diamondCut{cuts: cuts, init: init, calldata: calldata}, nil
+}
+
+// facetActionName IDiamondCut.FacetCutAction değerinin adı
+func facetActionName(a uint8) string {
+	switch a {
+	case 0:
+		return "Add"
+	case 1:
+		return "Replace"
+	case 2:
+		return "Remove"
+	}
+	return fmt.Sprintf("Bilinmeyen:%d", a)
+}
+
+// resolvedSelector selector ve ABI'lerden çözülen fonksiyon imzası
+type resolvedSelector struct {
+	hex string
+	sig string
+	ok  bool
+}
+
+// resolveCutSelectors facetin selector'larını önce facet, sonra diamond ABI'sinden, son olarak tüm ABI'lerden çözer.
+// Remove'da facet adresi sıfırdır; çözüm diamond ve genel ABI'lere kalır.
+func resolveCutSelectors(cut facetCut, diamond common.Address) []resolvedSelector {
+	out := make([]resolvedSelector, len(cut.FunctionSelectors))
+	for i, sel := range cut.FunctionSelectors {
+		sig, ok := resolveSelector(sel, cut.FacetAddress, diamond)
+		out[i] = resolvedSelector{hex: "0x" + hex.EncodeToString(sel[:]), sig: sig, ok: ok}
+	}
+	return out
+}
+
+// Facet başına listelenen çözülmüş selector sınırı (mesaj boyutu için); bilinmeyenler her zaman listelenir
+const maxListedSelectors = 15
+
+// renderDiamondCut DiamondCut bildirim gövdesini üretir: facet başına eylem ve selector listesi, init çağrısı.
+// Çözülemeyen selector'lar ⚠️ ile işaretlenir ve sayıları ayrıca verilir.
+func renderDiamondCut(ch *chainInstance, lg types.Log, dc *diamondCut) string {
+	var b strings.Builder
+	unknown := 0
+	for _, cut := range dc.cuts {
+		facet := "-"
+		if cut.FacetAddress != (common.Address{}) {
+			facet = labelAddress(ch, cut.FacetAddress)
+		}
+		b.WriteString(fmt.Sprintf("🔧 **Facet:** `%s` `%s` `%d selector`\n", facet, facetActionName(cut.Action), len(cut.FunctionSelectors)))
+		known, hidden := 0, 0
+		for _, s := range resolveCutSelectors(cut, lg.Address) {
+			switch {
+			case !s.ok:
+				unknown++
+				b.WriteString(fmt.Sprintf("⚠️ `%s` `bilinmeyen selector`\n", s.hex))
+			case known < maxListedSelectors:
+				known++
+				b.WriteString(fmt.Sprintf("▫️ `%s` `%s`\n", s.hex, s.sig))
+			default:
+				hidden++
+			}
+		}
+		if hidden > 0 {
+			b.WriteString(fmt.Sprintf("▫️ `+%d bilinen selector`\n", hidden))
+		}
+	}
+	if dc.init == (common.Address{}) {
+		b.WriteString("🧩 **Init:** `yok`\n")
+	} else {
+		call := fmt.Sprintf("%d byte", len(dc.calldata))
+		if len(dc.calldata) >= 4 {
+			var sel [4]byte
+			copy(sel[:], dc.calldata[:4])
+			if sig, ok := resolveSelector(sel, dc.init, lg.Address); ok {
+				call = sig + ", " + call
+			} else {
+				unknown++
+				call = "0x" + hex.EncodeToString(sel[:]) + " bilinmeyen, " + call
+			}
+		}
+		b.WriteString(fmt.Sprintf("🧩 **Init:** `%s` `%s`\n", labelAddress(ch, dc.init), call))
+	}
+	if unknown > 0 {
+		b.WriteString(fmt.Sprintf("🚨 **Çözülemeyen selector:** `%d`\n", unknown))
+	}
+	return b.String()
+}
+
+// summarizeDiamondCut tx bildirimi için tek satırlık özet (eylem başına selector sayısı)
+func summarizeDiamondCut(lg types.Log, dc *diamondCut) string {
+	counts := map[string]int{}
+	var order []string
+	unknown := 0
+	for _, cut := range dc.cuts {
+		a := facetActionName(cut.Action)
+		if _, ok := counts[a]; !ok {
+			order = append(order, a)
+		}
+		counts[a] += len(cut.FunctionSelectors)
+		for _, s := range resolveCutSelectors(cut, lg.Address) {
+			if !s.ok {
+				unknown++
+			}
+		}
+	}
+	parts := make([]string, 0, len(order)+1)
+	for _, a := range order {
+		parts = append(parts, fmt.Sprintf("%s %d", a, counts[a]))
+	}
+	if unknown > 0 {
+		parts = append(parts, fmt.Sprintf("⚠️ %d bilinmeyen", unknown))
+	}
+	return strings.Join(parts, ", ")
+}
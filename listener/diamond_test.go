@@ -0,0 +1,60 @@
+package listener
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeDiamondCut(t *testing.T) {
+	facet := common.HexToAddress("0x00000000000000000000000000000000000000f1")
+	initAddr := common.HexToAddress("0x00000000000000000000000000000000000000e2")
+	cuts := []facetCut{
+		{FacetAddress: facet, Action: 0, FunctionSelectors: [][4]byte{{0xa9, 0x05, 0x9c, 0xbb}, {0x09, 0x5e, 0xa7, 0xb3}}},
+		{FacetAddress: common.Address{}, Action: 2, FunctionSelectors: [][4]byte{{0x12, 0x34, 0x56, 0x78}}},
+	}
+	data, err := diamondCutArgs.Pack(cuts, initAddr, []byte{0xde, 0xad})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dc, err := decodeDiamondCut(types.Log{Topics: []common.Hash{diamondCutTopic}, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.init != initAddr || !bytes.Equal(dc.calldata, []byte{0xde, 0xad}) {
+		t.Errorf("init=%s calldata=%x", dc.init.Hex(), dc.calldata)
+	}
+	if len(dc.cuts) != len(cuts) {
+		t.Fatalf("%d cut, beklenen %d", len(dc.cuts), len(cuts))
+	}
+	for i, want := range cuts {
+		got := dc.cuts[i]
+		if got.FacetAddress != want.FacetAddress || got.Action != want.Action || len(got.FunctionSelectors) != len(want.FunctionSelectors) {
+			t.Fatalf("cut %d = %+v, beklenen %+v", i, got, want)
+		}
+		for j := range want.FunctionSelectors {
+			if got.FunctionSelectors[j] != want.FunctionSelectors[j] {
+				t.Errorf("cut %d selector %d = %x, beklenen %x", i, j, got.FunctionSelectors[j], want.FunctionSelectors[j])
+			}
+		}
+	}
+}
+
+func TestDecodeDiamondCutInvalid(t *testing.T) {
+	for _, data := range [][]byte{nil, {0x01, 0x02}, bytes.Repeat([]byte{0xff}, 96)} {
+		if _, err := decodeDiamondCut(types.Log{Topics: []common.Hash{diamondCutTopic}, Data: data}); err == nil {
+			t.Errorf("%x için hata bekleniyordu", data)
+		}
+	}
+}
+
+func TestFacetActionName(t *testing.T) {
+	for action, want := range map[uint8]string{0: "Add", 1: "Replace", 2: "Remove", 7: "Bilinmeyen:7"} {
+		if got := facetActionName(action); got != want {
+			t.Errorf("facetActionName(%d) = %s, beklenen %s", action, got, want)
+		}
+	}
+}
@@ -3,12 +3,10 @@ package listener
 import (
 	"context"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"log"
 	"math"
 	"math/big"
-	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -17,12 +15,9 @@ import (
 
 	"event-listener-backend/notifier"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // Event topic hashes
@@ -35,15 +30,8 @@ var (
 
 	// Özel cüzdan adresi
 	specialWallet = common.HexToAddress("0x049A025EA9e0807f2fd38c62923fCe688cBd8460")
-
-	// Native ETH bildirimlerinde de-dup icin
-	nativeSeen    = make(map[string]time.Time)
-	nativeSeenTTL = 10 * time.Minute
 )
 
-// Raw RPC client (HTTP) for robust block fetching across tx types
-var rawRPCClient *rpc.Client
-
 // removed: mutex no longer needed without parallel backfill
 
 // staticcheck U1000: Her derleme yolunda kullanılmış sayılması için garanti kullanım
@@ -59,85 +47,9 @@ func init() {
 	_ = estimateUSDValue
 	_ = formatWei
 	_ = handleLiveEvent
-	_ = bootstrapScanWindowed
-	_ = subscribeWithReconnect
-	_ = pollLogs
 	_ = TestImportanceFiltering
 }
 
-// adres->(topic0->event adı)
-var addressTopicNames = map[string]map[string]string{}
-
-// global topic0->event adı (adres bağımsız bilinen imzalar)
-var globalTopicNames = map[string]string{}
-
-func registerGlobalEvent(signature, name string) {
-	h := crypto.Keccak256Hash([]byte(signature))
-	globalTopicNames[strings.ToLower(h.Hex())] = name
-}
-
-func initGlobalEvents() {
-	// ERC20/standard
-	registerGlobalEvent("Transfer(address,address,uint256)", "Transfer")
-	registerGlobalEvent("Approval(address,address,uint256)", "Approval")
-	registerGlobalEvent("OwnershipTransferred(address,address)", "OwnershipTransferred")
-	// ERC1155-like
-	registerGlobalEvent("TransferSingle(address,address,address,uint256,uint256)", "TransferSingle")
-	// Diamond
-	registerGlobalEvent("DiamondCut((address,uint8,bytes4[])[],address,bytes)", "DiamondCut")
-	// Custom from provided ABIs
-	registerGlobalEvent("MsgInspectorSet(address)", "MsgInspectorSet")
-	registerGlobalEvent("CollateralWithdrawn(uint40,address,uint96)", "CollateralWithdrawn")
-	registerGlobalEvent("ColleteralDeposited(uint40,address,uint96)", "ColleteralDeposited")
-	registerGlobalEvent("Test_ColleteralDeposited(uint40,address,uint96)", "Test_ColleteralDeposited")
-	registerGlobalEvent("ItemListingCancelled(uint40,address,uint48)", "ItemListingCancelled")
-	registerGlobalEvent("ItemPriceUpdated(uint40,address,uint48,uint96,uint96)", "ItemPriceUpdated")
-	registerGlobalEvent("ItemSold(uint40,address,address,uint48,uint96,uint96,uint80)", "ItemSold")
-	registerGlobalEvent("NewItemListing(uint40,address,uint48,uint256)", "NewItemListing")
-	registerGlobalEvent("NewItemListingByAdmin(uint40,address,address,bool,uint48,uint256)", "NewItemListingByAdmin")
-	registerGlobalEvent("ReferralUsed(address,address,uint256)", "ReferralUsed")
-	registerGlobalEvent("groupAdded(address,uint256)", "groupAdded")
-	registerGlobalEvent("groupDeleted(address,uint256)", "groupDeleted")
-	registerGlobalEvent("groupDrawed(uint32,uint8,uint40)", "groupDrawed")
-	registerGlobalEvent("groupNftMintedEvent(string,uint32,uint40,uint40[])", "groupNftMintedEvent")
-	registerGlobalEvent("statusUpdated(address,uint256,uint256)", "statusUpdated")
-	registerGlobalEvent("InstallmentImported(uint32,uint8,uint32,uint32)", "InstallmentImported")
-	registerGlobalEvent("PxSetGrantAccess(address,address,bytes1[])", "PxSetGrantAccess")
-	registerGlobalEvent("PxUpdateGrantAccess(address,address,bytes32)", "PxUpdateGrantAccess")
-	registerGlobalEvent("DepositedAndCredited((bytes4,address,uint40))", "DepositedAndCredited")
-	registerGlobalEvent("MessageReceived(bytes4,address,uint40,uint256)", "MessageReceived")
-	registerGlobalEvent("Receipt(address,bytes32,bytes)", "Receipt")
-	registerGlobalEvent("Sent((bytes32,uint64,(uint256,uint256)),(uint256,uint256))", "Sent")
-	registerGlobalEvent("TransferERC20(address,address,address,uint256)", "TransferERC20")
-	registerGlobalEvent("firstDepositPaid(address,uint32,uint40,address,uint96,address,uint256,address,uint96,bool)", "firstDepositPaid")
-	registerGlobalEvent("installmentPaid(address,uint32,uint40,uint8,uint96)", "installmentPaid")
-	registerGlobalEvent("withdrawPaid(address,uint256,uint256,address,address,bool)", "withdrawPaid")
-	registerGlobalEvent("CollateralLiquidated(uint40,address,uint256,uint256)", "CollateralLiquidated")
-	registerGlobalEvent("CollateralLiquidatedForInstallment(uint40,address,uint256,uint256)", "CollateralLiquidatedForInstallment")
-	registerGlobalEvent("ReceivableAllocatedAsCollateral(uint40,address,uint256,uint256,uint256,address)", "ReceivableAllocatedAsCollateral")
-	registerGlobalEvent("SwapExecutedWithAmount(uint40,address,address,uint256,uint256)", "SwapExecutedWithAmount")
-	registerGlobalEvent("SwapExecutedWithPercentage(uint40,address,address,uint256,uint256)", "SwapExecutedWithPercentage")
-	registerGlobalEvent("rewardsclaimed(address,uint256)", "rewardsclaimed")
-}
-
-func resolveEventName(addr common.Address, topic0 common.Hash) string {
-	addrKey := strings.ToLower(addr.Hex())
-	if m, ok := addressTopicNames[addrKey]; ok {
-		if n, ok2 := m[strings.ToLower(topic0.Hex())]; ok2 {
-			return n
-		}
-	}
-	if n, ok := globalTopicNames[strings.ToLower(topic0.Hex())]; ok {
-		return n
-	}
-	// kısa hash fallback
-	s := topic0.Hex()
-	if len(s) > 10 {
-		return "Event " + s[:10]
-	}
-	return "Event " + s
-}
-
 // Global notifier listesi
 var notifiers []notifier.Notifier
 
@@ -146,11 +58,23 @@ type notificationItem struct {
 	title string
 	body  string
 	time  time.Time
+	refs  []logRef // bildirimin dayandığı on-chain kayıtlar (reorg takibi için)
+
+	// "edit" onay modunda: gereken derinlik ve sonradan değiştirilecek gövde satırı
+	confirmDepth  uint64
+	confirmMarker string
+
+	// Dedup sahiplenildikten sonra allowance tablosuna işlenecek onay logları
+	approvals []pendingApproval
+	// Dedup sahiplenildikten sonra slot durumuna kaydedilecek proxy logları
+	proxySlots []pendingProxySlot
 }
 
 var (
 	notificationBuffer = make(chan notificationItem, 100)
 	notificationTicker *time.Ticker
+	// Kapanışta işlemciden kalan bildirimleri gönderip durmasını ister
+	notificationDrain = make(chan chan struct{})
 )
 
 // Dinamik token fiyat önbelleği
@@ -160,10 +84,16 @@ type tokenPriceEntry struct {
 }
 
 var (
-	tokenPriceCache = make(map[string]tokenPriceEntry)
-	tokenPriceTTL   = 30 * time.Second // Varsayılan cache süresi (30 saniye)
+	tokenPriceCache = make(map[string]tokenPriceEntry) // anahtar: zincir:tokenAdresi
+	tokenPriceMu    sync.Mutex                         // zincir pipeline'ları paralel çalışır
+	tokenPriceTTL   = 30 * time.Second                 // Varsayılan cache süresi (30 saniye)
 )
 
+// priceCacheKey fiyat cache anahtarını üretir (aynı adres farklı zincirlerde farklı token olabilir)
+func priceCacheKey(ch *chainInstance, addr string) string {
+	return ch.name() + ":" + addr
+}
+
 // getTokenPriceTTL cache süresini ortam değişkeninden alır
 func getTokenPriceTTL() time.Duration {
 	if v := strings.TrimSpace(os.Getenv("TOKEN_PRICE_CACHE_TTL")); v != "" {
@@ -206,18 +136,6 @@ func getNativeUSDPrice() float64 {
 	return 3000.0
 }
 
-// RegisterEventName belirli bir adres + topic0 için ad kaydeder
-func RegisterEventName(addr common.Address, topic0 common.Hash, name string) {
-	addrKey := strings.ToLower(addr.Hex())
-	topicKey := strings.ToLower(topic0.Hex())
-	m, ok := addressTopicNames[addrKey]
-	if !ok {
-		m = map[string]string{}
-		addressTopicNames[addrKey] = m
-	}
-	m[topicKey] = name
-}
-
 // InitNotifiers bildirim kanallarını başlatır
 func InitNotifiers() {
 	// Telegram notifier'ı ekle
@@ -241,9 +159,25 @@ func InitNotifiersWithBot() {
 
 // SendNotificationToAllNotifiers tüm aktif notifier'lara bildirim gönderir
 func SendNotificationToAllNotifiers(title, body string) {
+	deliverNotification(title, body)
+}
+
+// deliverNotification bildirimi gönderir ve bot üzerinden gönderilen ana mesajın
+// chat/message id bilgisini döner (retraction gibi takip mesajları için)
+func deliverNotification(title, body string) sentRef {
+	var sent sentRef
+	sent.title = title
+
 	// Markdown formatında kalın başlık
 	formattedTitle := "*" + escapeMarkdownV2(title) + "*"
 	message := fmt.Sprintf("%s\n\n%s", formattedTitle, body)
+	sent.text = message
+
+	// Replay: notifier'lara ve bota gitmez, çıktı dosyasına yazılır
+	if getEventSourceKind() == eventSourceReplay {
+		writeReplayNotification(title, body, determineImportance(title, body))
+		return sent
+	}
 
 	// Mevcut notifier'ları kullan
 	for _, n := range notifiers {
@@ -264,10 +198,13 @@ func SendNotificationToAllNotifiers(title, body string) {
 			// ÖNEMLİ EVENTLER (ModuleInstalled veya 250+ USDT Transfer): GRUP 2
 			if chatID2 != 0 {
 				// Önce ana mesajı gönder
-				if err := bot.SendMessage(int(chatID2), message); err != nil {
+				if msgID, err := bot.SendMessageWithID(int(chatID2), message); err != nil {
 					log.Printf("❌ Bot bildirim (GRUP 2 - ÖNEMLİ) hatası: %v", err)
-				} else if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
-					log.Printf("✅ Önemli event GRUP 2'ye gönderildi: %s", title)
+				} else {
+					sent.chatID, sent.messageID = int(chatID2), msgID
+					if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+						log.Printf("✅ Önemli event GRUP 2'ye gönderildi: %s", title)
+					}
 				}
 				// Ardından 4 adet alarm mesajı gönder (1s arayla)
 				if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
@@ -282,10 +219,13 @@ func SendNotificationToAllNotifiers(title, body string) {
 				if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
 					log.Printf("↩️ GRUP 2 tanımsız, önemli event GRUP 1'e gönderilecek - title=%q", title)
 				}
-				if err := bot.SendMessage(int(chatID), message); err != nil {
+				if msgID, err := bot.SendMessageWithID(int(chatID), message); err != nil {
 					log.Printf("❌ Bot bildirim (GRUP 1 fallback - ÖNEMLİ) hatası: %v", err)
-				} else if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
-					log.Printf("⚠️ Önemli event GRUP 1'e fallback gönderildi (GRUP 2 yok): %s", title)
+				} else {
+					sent.chatID, sent.messageID = int(chatID), msgID
+					if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+						log.Printf("⚠️ Önemli event GRUP 1'e fallback gönderildi (GRUP 2 yok): %s", title)
+					}
 				}
 			} else {
 				log.Printf("⚠️ Hiçbir TELEGRAM_CHAT_ID tanımlı değil, önemli bildirim atlandı: %s", title)
@@ -293,22 +233,29 @@ func SendNotificationToAllNotifiers(title, body string) {
 		} else {
 			// NORMAL/ÖNEMSİZ EVENTLER: GRUP 1
 			if chatID != 0 {
-				if err := bot.SendMessage(int(chatID), message); err != nil {
+				if msgID, err := bot.SendMessageWithID(int(chatID), message); err != nil {
 					log.Printf("❌ Bot bildirim (GRUP 1 - NORMAL) hatası: %v", err)
-				} else if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
-					log.Printf("✅ Normal event GRUP 1'e gönderildi: %s", title)
+				} else {
+					sent.chatID, sent.messageID = int(chatID), msgID
+					if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+						log.Printf("✅ Normal event GRUP 1'e gönderildi: %s", title)
+					}
 				}
 			} else if chatID2 != 0 {
-				if err := bot.SendMessage(int(chatID2), message); err != nil {
+				if msgID, err := bot.SendMessageWithID(int(chatID2), message); err != nil {
 					log.Printf("❌ Bot bildirim (GRUP 2 fallback - NORMAL) hatası: %v", err)
-				} else if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
-					log.Printf("⚠️ Normal event GRUP 2'ye fallback gönderildi (GRUP 1 yok): %s", title)
+				} else {
+					sent.chatID, sent.messageID = int(chatID2), msgID
+					if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+						log.Printf("⚠️ Normal event GRUP 2'ye fallback gönderildi (GRUP 1 yok): %s", title)
+					}
 				}
 			} else {
 				log.Printf("⚠️ Hiçbir TELEGRAM_CHAT_ID tanımlı değil, normal bildirim atlandı: %s", title)
 			}
 		}
 	}
+	return sent
 }
 
 // sendPreAlarms: Önemli eventlerden önce dikkat çekici 4 ayrı alarm mesajı gönderir
@@ -360,7 +307,26 @@ func determineImportance(title, body string) bool {
 		return true
 	}
 
-	// 3) Transfer eventleri: Tek USD eşiği (env ile değiştirilebilir)
+	// 3) Proxy yükseltmeleri ve admin değişiklikleri (EIP-1967) her zaman önemli
+	{
+		lt := strings.ToLower(title)
+		if strings.Contains(lt, "upgraded") || strings.Contains(lt, "adminchanged") || strings.Contains(lt, "proxyslotchanged") {
+			if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+				log.Printf("✅ Proxy değişikliği tespit edildi - ÖNEMLİ (Grup 2)")
+			}
+			return true
+		}
+	}
+
+	// 4) Riskli onaylar (sınırsız/büyük allowance, listede olmayan spender) her zaman önemli
+	if strings.Contains(body, "🚨 **Risk:**") {
+		if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+			log.Printf("✅ Riskli approval tespit edildi - ÖNEMLİ (Grup 2)")
+		}
+		return true
+	}
+
+	// 5) Transfer eventleri: Tek USD eşiği (env ile değiştirilebilir)
 	if strings.Contains(title, "Transfer") {
 		// USD değerini çıkar
 		usd := extractUSDFromBody(body)
@@ -388,7 +354,7 @@ func determineImportance(title, body string) bool {
 		return isImportant
 	}
 
-	// 4) Diğer tüm eventler önemsiz (grup 1'e gidecek)
+	// 6) Diğer tüm eventler önemsiz (grup 1'e gidecek)
 	if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
 		log.Printf("ℹ️ Diğer event tespit edildi - NORMAL (Grup 1)")
 	}
@@ -472,6 +438,12 @@ func getChatID2() int64 {
 	return chatID
 }
 
+// escapeCodeSpan metni MarkdownV2 kod bloğu (`...`) içine konabilecek hale getirir: bloğun içinde
+// yalnızca ters eğik çizgi ve backtick kaçırılır (önce \ sonra `)
+func escapeCodeSpan(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "\\", "\\\\"), "`", "\\`")
+}
+
 // escapeMarkdownV2 Telegram MarkdownV2 için özel karakterleri escape eder
 func escapeMarkdownV2(text string) string {
 	// Telegram MarkdownV2'de escape edilmesi gereken karakterler
@@ -523,25 +495,40 @@ func TestImportanceFiltering() {
 	log.Println("✅ Filtreleme testi tamamlandı")
 }
 
-func formatEventMessage(lg types.Log) (string, string) {
-	cat := GetCategoryLabel(lg.Address)
+func formatEventMessage(ch *chainInstance, lg types.Log, txd txDetails) (string, string) {
+	cat := ch.category(lg.Address)
 	tx := lg.TxHash.Hex()
 
 	// InstallModule event - Kırmızı top (en önemli)
 	if len(lg.Topics) > 0 && lg.Topics[0] == moduleInstalledTopic {
 		moduleId := hex.EncodeToString(lg.Data)
 		title := "🔴 [" + cat + "] InstallModule"
-		body := fmt.Sprintf("📋 **Tx:** `%s`\n🔧 **Modül:** `%s`\n⏰ **Zaman:** `%s`", tx, moduleId, time.Now().Format("02.01.2006 15:04:05"))
+		body := fmt.Sprintf("📋 **Tx:** `%s`\n🔧 **Modül:** `%s`\n%s", tx, moduleId, txd.footer())
 		return title, body
 	}
 
+	// Proxy yükseltmesi / admin değişikliği (EIP-1967) - her zaman önemli
+	if isProxyLog(lg) {
+		return formatProxyMessage(ch, lg, txd)
+	}
+
+	// NFT transferleri (ERC-721 Transfer, ERC-1155 TransferSingle/TransferBatch)
+	if isNFTTransferLog(lg) {
+		return formatNFTMessage(ch, lg, txd)
+	}
+
+	// Approval / ApprovalForAll (ERC-20, ERC-721, ERC-1155)
+	if isApprovalLog(lg) {
+		return formatApprovalMessage(ch, lg, txd)
+	}
+
 	// Transfer event
 	if len(lg.Topics) > 0 && lg.Topics[0] == transferTopic {
-		d := parseTransferDetails(lg)
+		d := parseTransferDetails(ch, lg)
 		if d != nil {
 			// Yalnızca izlenen cüzdanları ilgilendiren transferleri bildir
-			fromWatched := WatchMap[strings.ToLower(d.from.Hex())]
-			toWatched := WatchMap[strings.ToLower(d.to.Hex())]
+			fromWatched := ch.isWatched(strings.ToLower(d.from.Hex()))
+			toWatched := ch.isWatched(strings.ToLower(d.to.Hex()))
 			if !fromWatched && !toWatched {
 				return "", ""
 			}
@@ -550,8 +537,8 @@ func formatEventMessage(lg types.Log) (string, string) {
 			body.WriteString(fmt.Sprintf("📤 **From:** `%s`\n", d.from.Hex()))
 			body.WriteString(fmt.Sprintf("📥 **To:** `%s`\n", d.to.Hex()))
 			// Token sembolü ve doğru ondalıkla değer
-			sym := getAssetSymbol(lg.Address)
-			dec := getTokenDecimals(lg.Address)
+			sym := getAssetSymbol(ch, lg.Address)
+			dec := getTokenDecimals(ch, lg.Address)
 
 			// Debug: Token adresi ve decimal bilgisi
 			log.Printf("🔍 Transfer debug: token=%s, symbol=%s, decimal=%d, value=%s, raw_value=%s",
@@ -582,7 +569,7 @@ func formatEventMessage(lg types.Log) (string, string) {
 			if d.isSpecialWalletInvolved {
 				body.WriteString("🚨 **ÖZEL CÜZDAN İLGİLİ**\n")
 			}
-			body.WriteString(fmt.Sprintf("⏰ **Zaman:** `%s`", time.Now().Format("02.01.2006 15:04:05")))
+			body.WriteString(txd.footer())
 
 			// Önem tespiti (emoji seçimi)
 			computedBody := body.String()
@@ -608,12 +595,28 @@ func formatEventMessage(lg types.Log) (string, string) {
 	// DiamondCut'i InstallModule olarak ele al (önemli kabul edilecek)
 	if strings.EqualFold(eventName, "DiamondCut") {
 		title := "🔴 [" + cat + "] InstallModule"
-		body := fmt.Sprintf("📋 **Tx:** `%s`\n⚙️ **Event:** `DiamondCut→InstallModule`\n⏰ **Zaman:** `%s`", tx, time.Now().Format("02.01.2006 15:04:05"))
+		// Facet/selector değişiklikleri; çözülemezse yalnızca event bilgisi
+		details := ""
+		if lg.Topics[0] == diamondCutTopic {
+			if dc, err := decodeDiamondCut(lg); err == nil {
+				details = renderDiamondCut(ch, lg, dc)
+			} else {
+				log.Printf("⚠️ DiamondCut çözülemedi (tx %s): %v", tx, err)
+			}
+		}
+		body := fmt.Sprintf("📋 **Tx:** `%s`\n⚙️ **Event:** `DiamondCut→InstallModule`\n%s%s", tx, details, txd.footer())
+		return title, body
+	}
+
+	// listener/abis'ten üretilmiş tipli çözücüler (go generate ./listener)
+	if title, body, ok := formatGeneratedLog(ch, lg, txd); ok {
 		return title, body
 	}
 
 	title := "🔵 [" + cat + "] " + eventName // Normal (Grup 1)
-	body := fmt.Sprintf("📋 **Tx:** `%s`\n⏰ **Zaman:** `%s`", tx, time.Now().Format("02.01.2006 15:04:05"))
+	// Event argümanları (ABI ya da imzadan çözümlenebiliyorsa)
+	fields := renderDecodedFields(decodeEventFields(ch, lg))
+	body := fmt.Sprintf("📋 **Tx:** `%s`\n%s%s", tx, fields, txd.footer())
 	return title, body
 }
 
@@ -626,8 +629,9 @@ type transferDetails struct {
 	isSpecialWalletInvolved bool
 }
 
-func parseTransferDetails(lg types.Log) *transferDetails {
-	if len(lg.Topics) < 3 {
+func parseTransferDetails(ch *chainInstance, lg types.Log) *transferDetails {
+	// ERC-721 Transfer'de tokenId 4. topic'tedir, değer değildir (parseNFTTransfer)
+	if len(lg.Topics) != 3 {
 		return nil
 	}
 
@@ -641,7 +645,7 @@ func parseTransferDetails(lg types.Log) *transferDetails {
 		value = big.NewInt(0)
 	}
 
-	usd := estimateUSDValue(value, lg.Address)
+	usd := estimateUSDValue(ch, value, lg.Address)
 	return &transferDetails{
 		from:                    from,
 		to:                      to,
@@ -657,12 +661,12 @@ func isSpecialTransfer(from, to common.Address) bool {
 }
 
 // estimateUSDValue yaklaşık USD değerini hesaplar
-func estimateUSDValue(value *big.Int, tokenAddr common.Address) float64 {
+func estimateUSDValue(ch *chainInstance, value *big.Int, tokenAddr common.Address) float64 {
 	// Debug: Gelen token adresi
 	log.Printf("🔍 estimateUSDValue: token=%s, value=%s", tokenAddr.Hex(), value.String())
 
 	// Dinamik fiyat çek (cache + DexScreener)
-	price := fetchTokenUSDPrice(tokenAddr)
+	price := fetchTokenUSDPrice(ch, tokenAddr)
 	log.Printf("🔍 Fiyat çekildi: price=%f", price)
 
 	if price <= 0 {
@@ -673,7 +677,7 @@ func estimateUSDValue(value *big.Int, tokenAddr common.Address) float64 {
 			price = 1.0
 		} else {
 			// Bilinmeyen token'lar için güvenlik kontrolü
-			if _, ok := tokenSymbols[strings.ToLower(tokenAddr.Hex())]; !ok {
+			if getAssetSymbol(ch, tokenAddr) == "" {
 				log.Printf("🔒 Bilinmeyen token için fiyat hesaplaması devre dışı: %s", tokenAddr.Hex())
 				return 0
 			}
@@ -683,7 +687,7 @@ func estimateUSDValue(value *big.Int, tokenAddr common.Address) float64 {
 	}
 
 	// Token'ın gerçek decimal'ını kullan
-	decimals := getTokenDecimals(tokenAddr)
+	decimals := getTokenDecimals(ch, tokenAddr)
 	log.Printf("🔍 Decimal: %d", decimals)
 
 	// Güvenlik kontrolü: Çok büyük decimal değerleri
@@ -710,7 +714,7 @@ func estimateUSDValue(value *big.Int, tokenAddr common.Address) float64 {
 	if usdValue > 1000000 {
 		log.Printf("⚠️ Çok büyük USD değeri tespit edildi: $%.2f, kontrol ediliyor", usdValue)
 		// Fiyatı tekrar kontrol et
-		price = fetchTokenUSDPrice(tokenAddr)
+		price = fetchTokenUSDPrice(ch, tokenAddr)
 		if price > 0 {
 			priceFloat = new(big.Float).SetFloat64(price)
 			result = new(big.Float).Mul(valueFloat, priceFloat)
@@ -720,14 +724,14 @@ func estimateUSDValue(value *big.Int, tokenAddr common.Address) float64 {
 	}
 
 	// 2. Bilinen token'lar için ek kontroller
-	if symbol, ok := tokenSymbols[strings.ToLower(tokenAddr.Hex())]; ok {
+	if symbol, ok := ch.symbol(strings.ToLower(tokenAddr.Hex())); ok {
 		switch symbol {
 		case "USDC", "USDT":
 			// USDC/USDT için 0.15$ = 461$ olmamalı
 			if usdValue > 100 && usdValue < 1000 {
 				log.Printf("⚠️ %s için şüpheli USD değeri: $%.2f, kontrol ediliyor", symbol, usdValue)
 				// Fiyatı tekrar kontrol et
-				price = fetchTokenUSDPrice(tokenAddr)
+				price = fetchTokenUSDPrice(ch, tokenAddr)
 				if price > 0 {
 					priceFloat = new(big.Float).SetFloat64(price)
 					result = new(big.Float).Mul(valueFloat, priceFloat)
@@ -749,67 +753,46 @@ func estimateUSDValue(value *big.Int, tokenAddr common.Address) float64 {
 	return usdValue
 }
 
-// Çoklu kaynaklardan token USD fiyatı çek (DexScreener, CoinGecko, 1inch)
-func fetchTokenUSDPrice(tokenAddr common.Address) float64 {
+// Çoklu kaynaklardan token USD fiyatı çek (DexScreener, CoinGecko, on-chain feed, sabit fiyat)
+func fetchTokenUSDPrice(ch *chainInstance, tokenAddr common.Address) float64 {
 	addr := strings.ToLower(tokenAddr.Hex())
 	if addr == "" || addr == "0x0000000000000000000000000000000000000000" {
 		return 0
 	}
+	key := priceCacheKey(ch, addr)
 
 	// Cache kontrolü
-	if ent, ok := tokenPriceCache[addr]; ok {
-		if time.Since(ent.cachedAt) < getTokenPriceTTL() {
-			return ent.price
-		}
+	tokenPriceMu.Lock()
+	ent, ok := tokenPriceCache[key]
+	tokenPriceMu.Unlock()
+	if ok && time.Since(ent.cachedAt) < getTokenPriceTTL() {
+		return ent.price
 	}
 
-	// Çoklu kaynaklardan fiyat çek (fallback mekanizması ile)
-	price := fetchFromMultipleSources(addr)
+	// Kaynakları paralel sorgula, medyanı al, aykırı fiyatları ele
+	price, breakdown := fetchFromMultipleSources(ch, addr)
 
 	// Stablecoin fiyat güvenliği: USDC/USDT için 1.0'a sabitle
-	if sym, ok := tokenSymbols[addr]; ok && (sym == "USDC" || sym == "USDT") {
+	if sym, ok := ch.symbol(addr); ok && (sym == "USDC" || sym == "USDT") {
 		if price < 0.9 || price > 1.1 {
 			log.Printf("⚠️ Stablecoin %s için anormal fiyat: $%.4f → 1.0'a sabitleniyor", sym, price)
 		}
 		price = 1.0
+		breakdown.Note = "stablecoin fiyatı 1.0'a sabitlendi"
 	}
+	breakdown.Price = price
+	storePriceBreakdown(breakdown)
 
 	if price > 0 {
-		tokenPriceCache[addr] = tokenPriceEntry{price: price, cachedAt: time.Now()}
+		tokenPriceMu.Lock()
+		tokenPriceCache[key] = tokenPriceEntry{price: price, cachedAt: time.Now()}
+		tokenPriceMu.Unlock()
 		log.Printf("🔍 Fiyat güncellendi: token=%s, fiyat=$%.4f", addr, price)
 	}
 
 	return price
 }
 
-// Çoklu kaynaklardan fiyat çek (fallback mekanizması ile)
-func fetchFromMultipleSources(addr string) float64 {
-	// Rate limiting kontrolü
-	if isRateLimited() {
-		log.Printf("⚠️ Rate limit aktif, cache'den fiyat kullanılıyor: %s", addr)
-		return 0
-	}
-
-	// 1. DexScreener (ana kaynak - en güvenilir)
-	if price := fetchFromDexScreener(addr); price > 0 {
-		log.Printf("🔍 DexScreener'dan fiyat alındı: $%.4f", price)
-		return price
-	}
-
-	// 2. CoinGecko (fallback - güvenilir)
-	if price := fetchFromCoinGecko(addr); price > 0 {
-		log.Printf("🔍 CoinGecko'dan fiyat alındı: $%.4f", price)
-		return price
-	}
-
-	// 3. 1inch DEVRE DIŞI (güvenlik nedeniyle)
-	// 1inch API'si yanlış fiyatlar döndürebiliyor, bu yüzden devre dışı bırakıldı
-	log.Printf("⚠️ 1inch API devre dışı (güvenlik nedeniyle): %s", addr)
-
-	log.Printf("⚠️ Hiçbir kaynaktan fiyat alınamadı: %s", addr)
-	return 0
-}
-
 // Rate limiting kontrolü
 var (
 	lastRequestTime = time.Now()
@@ -838,115 +821,38 @@ func isRateLimited() bool {
 	return false
 }
 
-// DexScreener'dan fiyat çek
-func fetchFromDexScreener(addr string) float64 {
-	url := fmt.Sprintf("https://api.dexscreener.com/latest/dex/tokens/%s", addr)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return 0
-	}
-	req = req.WithContext(context.Background())
-	client := &http.Client{Timeout: 3 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return 0
-	}
-
-	var payload struct {
-		Pairs []struct {
-			PriceUsd string `json:"priceUsd"`
-			DexId    string `json:"dexId"`
-		} `json:"pairs"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return 0
-	}
-
-	best := 0.0
-	bestDex := ""
-
-	// En yüksek fiyatı bul
-	for _, p := range payload.Pairs {
-		if p.PriceUsd == "" {
-			continue
-		}
-		if f, err := strconv.ParseFloat(p.PriceUsd, 64); err == nil && f > 0 {
-			if f > best {
-				best = f
-				bestDex = p.DexId
-			}
-		}
-	}
-
-	if best > 0 {
-		log.Printf("🔍 DexScreener: %s, fiyat=$%.4f, DEX=%s", addr, best, bestDex)
-	}
-
-	return best
-}
-
-// CoinGecko'dan fiyat çek (Arbitrum için)
-func fetchFromCoinGecko(addr string) float64 {
-	// CoinGecko Arbitrum token listesi
-	url := "https://api.coingecko.com/api/v3/simple/token_price/arbitrum-one"
-	params := fmt.Sprintf("?contract_addresses=%s&vs_currencies=usd", addr)
-
-	req, err := http.NewRequest("GET", url+params, nil)
-	if err != nil {
-		return 0
-	}
-	req = req.WithContext(context.Background())
-	client := &http.Client{Timeout: 3 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0
-	}
-	defer resp.Body.Close()
-	if resp.StatusCode != 200 {
-		return 0
-	}
-
-	var payload map[string]map[string]float64
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return 0
-	}
-
-	if tokenData, exists := payload[addr]; exists {
-		if price, exists := tokenData["usd"]; exists && price > 0 {
-			log.Printf("🔍 CoinGecko: %s, fiyat=$%.4f", addr, price)
-			return price
-		}
-	}
-
-	return 0
-}
-
 // ClearTokenPriceCache belirli bir token'ın fiyat cache'ini temizler
 func ClearTokenPriceCache(tokenAddr common.Address) {
 	addr := strings.ToLower(tokenAddr.Hex())
-	delete(tokenPriceCache, addr)
+	tokenPriceMu.Lock()
+	for _, ch := range activeChains() {
+		delete(tokenPriceCache, priceCacheKey(ch, addr))
+	}
+	tokenPriceMu.Unlock()
 	log.Printf("🔍 Fiyat cache temizlendi: %s", addr)
 }
 
 // ClearAllTokenPriceCache tüm fiyat cache'ini temizler
 func ClearAllTokenPriceCache() {
+	tokenPriceMu.Lock()
 	tokenPriceCache = make(map[string]tokenPriceEntry)
+	tokenPriceMu.Unlock()
 	log.Printf("🔍 Tüm fiyat cache temizlendi")
-	log.Printf("🔒 Güvenlik: 1inch API devre dışı, sadece DexScreener ve CoinGecko kullanılıyor")
+	log.Printf("🔒 Güvenlik: 1inch API devre dışı, fiyat kaynakları: DexScreener, CoinGecko, on-chain feed, sabit fiyat (medyan)")
 }
 
 // ForceRefreshTokenPrice belirli bir token'ın fiyatını zorla yeniler
 func ForceRefreshTokenPrice(tokenAddr common.Address) {
 	addr := strings.ToLower(tokenAddr.Hex())
-	delete(tokenPriceCache, addr)
+	tokenPriceMu.Lock()
+	for _, ch := range activeChains() {
+		delete(tokenPriceCache, priceCacheKey(ch, addr))
+	}
+	tokenPriceMu.Unlock()
 	log.Printf("🔍 Token fiyatı zorla yenilendi: %s", addr)
 }
 
-// Token sembolleri (bilinen adresler)
+// Token sembolleri (varsayılan zincirin bilinen adresleri; diğer zincirler CHAINS_FILE'dan)
 var tokenSymbols = map[string]string{
 	strings.ToLower("0xFd086bC7CD5C481DCC9C85ebE478A1C0b69FCbb9"): "USDT",
 	strings.ToLower("0x82aF49447D8a07e3bd95BD0d56f35241523fBab1"): "WETH",
@@ -966,9 +872,9 @@ var tokenDecimals = map[string]int{
 	strings.ToLower("0xc5eFb9E4EfD91E68948d5039819494Eea56FFA46"): 18, // PAXG
 }
 
-func getTokenDecimals(addr common.Address) int {
+func getTokenDecimals(ch *chainInstance, addr common.Address) int {
 	addrLower := strings.ToLower(addr.Hex())
-	if d, ok := tokenDecimals[addrLower]; ok {
+	if d, ok := ch.decimals(addrLower); ok {
 		// Debug: USDC için decimal kontrolü
 		if strings.EqualFold(addrLower, "0xaf88d065e77c8cc2239327c5edb3a432268e5831") ||
 			strings.EqualFold(addrLower, "0xea1523eb5f0ecddb1875122ac2c9470a978e3010") {
@@ -985,6 +891,11 @@ func getTokenDecimals(addr common.Address) int {
 		return d
 	}
 
+	// Kayıtta olmayan token: decimals() ile keşfedilip diskte cache'lenir
+	if d, ok := discoveredDecimals(ch, addr); ok {
+		return d
+	}
+
 	// Bilinmeyen token'lar için daha güvenli varsayılan
 	// Çoğu ERC20 token 18 decimal kullanır, ama bazıları farklı olabilir
 	log.Printf("⚠️ Bilinmeyen token decimal'ı, varsayılan 18 kullanılıyor: %s", addrLower)
@@ -994,8 +905,11 @@ func getTokenDecimals(addr common.Address) int {
 	return 18
 }
 
-func getAssetSymbol(addr common.Address) string {
-	if s, ok := tokenSymbols[strings.ToLower(addr.Hex())]; ok {
+func getAssetSymbol(ch *chainInstance, addr common.Address) string {
+	if s, ok := ch.symbol(strings.ToLower(addr.Hex())); ok {
+		return s
+	}
+	if s, ok := discoveredSymbol(ch, addr); ok {
 		return s
 	}
 	return ""
@@ -1102,48 +1016,87 @@ func formatTokenAmount(value *big.Int, decimals int) string {
 	return result
 }
 
-func handleLiveEvent(vLog types.Log) {
+func handleLiveEvent(ctx context.Context, ch *chainInstance, vLog types.Log) error {
+	// Reorg ile kaldırılan loglar: bildirimi düşür ya da geri al
+	if vLog.Removed {
+		handleRemovedLog(ch.name(), vLog)
+		return nil
+	}
+
 	// Yalnızca bizim adreslerle ilgili logları işle
-	if !isRelevantLog(vLog) {
-		return
+	if !isRelevantLog(ch, vLog) {
+		return nil
 	}
 
-	// Native ETH transferini kontrol et (tx.Value>0 ve taraflardan biri biz)
-	if vLog.TxHash != (common.Hash{}) {
-		if titleN, bodyN, ok := tryBuildNativeTransferNotification(vLog); ok {
-			select {
-			case notificationBuffer <- notificationItem{title: titleN, body: bodyN, time: time.Now()}:
-			default:
-				log.Println("⚠️ Bildirim buffer'ı dolu, native ETH bildirimi atlandı")
-			}
-			return
-		}
+	refs := []logRef{logRefOf(ch.name(), vLog)}
+	// Daha önce işlenmiş log (bootstrap/catch-up tekrarları, yeniden başlatma): fiyat çekmeden atla
+	if eventDedup.seenAll(refs) {
+		return nil
 	}
 
-	title, body := formatEventMessage(vLog)
+	item, ok := logNotification(ch, vLog, txDetails{})
+	if !ok {
+		return nil
+	}
+
+	// Bildirimi buffer'a ekle (buffer doluysa yer açılana kadar bekler)
+	return enqueueNotification(ctx, item)
+}
+
+// logNotification ilgili logu çözümleyip (fiyat dahil) bildirime çevirir; bildirim üretmeyen loglar için false
+func logNotification(ch *chainInstance, vLog types.Log, txd txDetails) (notificationItem, bool) {
+	// Native ETH transferleri pipeline'da blok taramasından ayrıca gelir
+	title, body := formatEventMessage(ch, vLog, txd)
+	if title == "" {
+		return notificationItem{}, false
+	}
+	return notificationItem{title: ch.titled(title), body: body, time: time.Now(), refs: []logRef{logRefOf(ch.name(), vLog)}, approvals: approvalOf(ch, vLog), proxySlots: proxySlotOf(ch, vLog)}, true
+}
 
-	// Bildirimi buffer'a ekle
+// enqueueNotification bildirimi (reorg takibine alarak) buffer'a ekler; buffer doluysa yer açılana
+// ya da ctx iptal edilene kadar bekler. Tüm kayıtları daha önce kuyruğa alınmış bildirimler
+// (chain, txHash, logIndex) tekrar eklenmez. Hata dönerse bildirim kuyruğa girmemiştir; çağıran
+// aralığın checkpoint'ini ilerletmemelidir.
+func enqueueNotification(ctx context.Context, item notificationItem) error {
+	if !eventDedup.claim(item.refs) {
+		if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+			log.Printf("🔁 Tekrarlanan event atlandı: %s", item.title)
+		}
+		return nil
+	}
+	trackRefs(item.refs)
 	select {
-	case notificationBuffer <- notificationItem{
-		title: title,
-		body:  body,
-		time:  time.Now(),
-	}:
-	default:
-		// Buffer doluysa eski bildirimi at
-		log.Println("⚠️ Bildirim buffer'ı dolu, eski bildirim atıldı")
+	case notificationBuffer <- item:
+		applyApprovals(item.approvals)
+		applyProxySlots(item.proxySlots)
+		return nil
+	case <-ctx.Done():
+		// Kuyruğa giremeyen bildirim aralık tekrar tarandığında yeniden üretilebilsin
+		eventDedup.forget(item.refs)
+		return ctx.Err()
 	}
 }
 
 // isRelevantLog: yalnızca bizim adreslerle ilgili logları kabul eder
 // - Transfer: from veya to bizim adreslerden biri olmalı
+// - Approval/ApprovalForAll: owner bizim adreslerden biri ya da kontrat bizim olmalı
 // - Diğer eventler: logu üreten kontrat bizim izlenen adreslerimizden biri olmalı
 // - Zero address transfer logları: from veya to bizim adreslerden biri olmalı
-func isRelevantLog(lg types.Log) bool {
+func isRelevantLog(ch *chainInstance, lg types.Log) bool {
 	if len(lg.Topics) == 0 {
 		return false
 	}
 	topic0 := lg.Topics[0]
+	// NFT transferleri (ERC-721, ERC-1155): operator/from/to ya da koleksiyon kontrol et
+	if isNFTTransferLog(lg) {
+		t := parseNFTTransfer(lg)
+		return t != nil && nftRelevant(ch, lg, t)
+	}
+	// Onaylar: owner izleniyorsa ya da onay bizim kontratımızda
+	if isApprovalLog(lg) {
+		owner := strings.ToLower(common.BytesToAddress(lg.Topics[1].Bytes()).Hex())
+		return ch.isWatched(owner) || ch.isWatched(strings.ToLower(lg.Address.Hex()))
+	}
 	// Transfer eventleri: from/to kontrol et
 	if topic0 == transferTopic {
 		if len(lg.Topics) < 3 {
@@ -1151,94 +1104,14 @@ func isRelevantLog(lg types.Log) bool {
 		}
 		from := strings.ToLower(common.BytesToAddress(lg.Topics[1].Bytes()).Hex())
 		to := strings.ToLower(common.BytesToAddress(lg.Topics[2].Bytes()).Hex())
-		return WatchMap[from] || WatchMap[to]
+		return ch.isWatched(from) || ch.isWatched(to)
 	}
 	// Diğer eventler: sadece bizim kontratlardan gelenleri kabul et
-	return WatchMap[strings.ToLower(lg.Address.Hex())]
+	return ch.isWatched(strings.ToLower(lg.Address.Hex()))
 }
 
 // removed: zero-address log tabanlı native tespit mantığı kaldırıldı (native log üretmez)
 
-// tryBuildNativeTransferNotification: tx.Value>0 ise ve taraflardan biri bizim adrese eşitse bildirim üretir
-func tryBuildNativeTransferNotification(lg types.Log) (string, string, bool) {
-	// De-dupe: aynı tx için tekrar üretme
-	txh := lg.TxHash.Hex()
-	// removed: mutex no longer needed without parallel backfill
-	if ts, ok := nativeSeen[txh]; ok {
-		if time.Since(ts) < nativeSeenTTL {
-			return "", "", false
-		}
-	}
-
-	rpcUrl := os.Getenv("ARBITRUM_RPC")
-	if strings.TrimSpace(rpcUrl) == "" {
-		return "", "", false
-	}
-	client, err := ethclient.Dial(rpcUrl)
-	if err != nil {
-		return "", "", false
-	}
-	defer client.Close()
-
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
-
-	tx, _, err := client.TransactionByHash(ctx, lg.TxHash)
-	if err != nil || tx == nil {
-		return "", "", false
-	}
-	if tx.Value() == nil || tx.Value().Sign() <= 0 {
-		return "", "", false
-	}
-
-	// To adresi
-	toAddr := ""
-	if tx.To() != nil {
-		toAddr = strings.ToLower(tx.To().Hex())
-	}
-
-	// From adresi (imzadan çıkar)
-	chainID, err := client.ChainID(ctx)
-	if err != nil {
-		return "", "", false
-	}
-	signer := types.LatestSignerForChainID(chainID)
-	fromAddress, err := types.Sender(signer, tx)
-	if err != nil {
-		return "", "", false
-	}
-	fromAddr := strings.ToLower(fromAddress.Hex())
-
-	// İlgililik: from/to bizim adreslerden biri olmalı
-	isToWatched := toAddr != "" && WatchMap[toAddr]
-	isFromWatched := WatchMap[fromAddr]
-	if !isToWatched && !isFromWatched {
-		return "", "", false
-	}
-
-	// Başlık/gövde
-	cat := "ETH"
-	emoji := "🔵"
-	valueEth := new(big.Float).Quo(new(big.Float).SetInt(tx.Value()), new(big.Float).SetFloat64(1e18))
-	valStr := valueEth.Text('f', 6)
-
-	dir := ""
-	if isFromWatched && isToWatched {
-		dir = "internal"
-	} else if isFromWatched {
-		dir = "out"
-	} else {
-		dir = "in"
-	}
-
-	title := emoji + " [" + cat + "] Transfer (ETH)"
-	body := fmt.Sprintf("📋 **Tx:** `%s`\n📤 **From:** `%s`\n📥 **To:** `%s`\n💰 **Value:** `%s ETH`\n🏷️ **Dir:** `%s`\n⏰ **Zaman:** `%s`",
-		lg.TxHash.Hex(), fromAddr, toAddr, valStr, dir, time.Now().Format("02.01.2006 15:04:05"))
-
-	nativeSeen[txh] = time.Now()
-	return title, body, true
-}
-
 // startNotificationProcessor bildirimleri gruplandırır ve gönderir
 func startNotificationProcessor() {
 	notificationTicker = time.NewTicker(5 * time.Second) // 5 saniyede bir gruplandır
@@ -1249,6 +1122,11 @@ func startNotificationProcessor() {
 		for {
 			select {
 			case item := <-notificationBuffer:
+				// Onay derinliği politikası: "hold" modunda bildirim derinliğe ulaşana kadar bekletilir
+				item, ok := admitForConfirmation(item)
+				if !ok {
+					continue
+				}
 				notifications = append(notifications, item)
 
 				// İsteğe bağlı: önemli bildirimleri anında gönder (IMMEDIATE_IMPORTANT=true)
@@ -1262,10 +1140,35 @@ func startNotificationProcessor() {
 				}
 
 			case <-notificationTicker.C:
+				notifications = append(notifications, releaseConfirmed()...)
+				confirmSentMessages()
 				if len(notifications) > 0 {
 					sendGroupedNotifications(notifications)
 					notifications = notifications[:0]
 				}
+
+			case done := <-notificationDrain:
+				// Kapanış: buffer'da ve batch'te kalanları gönder ve dur. Onay derinliği bekleyenler
+				// (hold) diskte saklanır, yeniden başlatmada geri yüklenir.
+				notificationTicker.Stop()
+				for {
+					select {
+					case item := <-notificationBuffer:
+						if item, ok := admitForConfirmation(item); ok {
+							notifications = append(notifications, item)
+						}
+						continue
+					default:
+					}
+					break
+				}
+				notifications = append(notifications, drainPendingConfirms()...)
+				if len(notifications) > 0 {
+					log.Printf("📤 Kapanış: %d bekleyen bildirim gönderiliyor", len(notifications))
+					sendGroupedNotifications(notifications)
+				}
+				close(done)
+				return
 			}
 		}
 	}()
@@ -1273,6 +1176,8 @@ func startNotificationProcessor() {
 
 // sendGroupedNotifications gruplandırılmış bildirimleri gönderir
 func sendGroupedNotifications(notifications []notificationItem) {
+	// Reorg ile geçersiz kalan (henüz gönderilmemiş) bildirimleri ayıkla
+	notifications = dropOrphaned(notifications)
 	if len(notifications) == 0 {
 		return
 	}
@@ -1280,7 +1185,7 @@ func sendGroupedNotifications(notifications []notificationItem) {
 	if len(notifications) == 1 {
 		// Tek bildirim
 		item := notifications[0]
-		SendNotificationToAllNotifiers(item.title, item.body)
+		afterSent(item, deliverNotification(item.title, item.body))
 		return
 	}
 
@@ -1290,7 +1195,7 @@ func sendGroupedNotifications(notifications []notificationItem) {
 		lb := strings.ToLower(it.body)
 		if strings.Contains(lt, "installmodule") || strings.Contains(lb, "installmodule") || strings.Contains(lt, "diamondcut") || strings.Contains(lb, "diamondcut→installmodule") {
 			for _, single := range notifications {
-				SendNotificationToAllNotifiers(single.title, single.body)
+				afterSent(single, deliverNotification(single.title, single.body))
 			}
 			return
 		}
@@ -1308,131 +1213,26 @@ func sendGroupedNotifications(notifications []notificationItem) {
 		body.WriteString(fmt.Sprintf("**%d\\.** %s\n%s\n\n", i+1, item.title, item.body))
 	}
 
-	SendNotificationToAllNotifiers(title, body.String())
-}
-
-func bootstrapScanWindowed(ctx context.Context, client *ethclient.Client) {
-	blocksEnv := strings.TrimSpace(os.Getenv("BOOTSTRAP_BLOCKS"))
-	if blocksEnv == "" {
-		blocksEnv = "2000"
-	}
-	total, err := strconv.Atoi(blocksEnv)
-	if err != nil || total <= 0 {
-		total = 2000
-	}
-
-	maxWindow := 500
-	if mwEnv := strings.TrimSpace(os.Getenv("BOOTSTRAP_MAX_WINDOW")); mwEnv != "" {
-		if v, err := strconv.Atoi(mwEnv); err == nil && v > 0 {
-			maxWindow = v
-		}
-	}
-
-	head, err := client.BlockNumber(ctx)
-	if err != nil {
-		log.Printf("⚠️ Son blok numarası alınamadı: %v", err)
-		return
-	}
-	if total > int(head) {
-		total = int(head)
-	}
-	from := int64(head) - int64(total)
-	if from < 0 {
-		from = 0
-	}
-
-	log.Printf("🔁 Bootstrap taraması: toplam %d blok, pencere=%d", total, maxWindow)
-	remaining := total
-	cursor := int64(from)
-	toHead := int64(head)
-
-	for remaining > 0 {
-		// Pencere boyutu (dahil aralık icin to=from+win-1)
-		win := int(math.Min(float64(maxWindow), float64(remaining)))
-		fromBlock := big.NewInt(cursor)
-		toIncl := cursor + int64(win) - 1
-		if toIncl > toHead {
-			toIncl = toHead
-		}
-		toBlock := big.NewInt(toIncl)
-
-		q := ethereum.FilterQuery{FromBlock: fromBlock, ToBlock: toBlock, Addresses: WatchAddresses}
-		logs, err := client.FilterLogs(ctx, q)
-		if err != nil {
-			log.Printf("⚠️ Bootstrap penceresi (%d-%d) başarısız: %v", fromBlock.Int64(), toBlock.Int64(), err)
-			time.Sleep(500 * time.Millisecond)
-		} else if len(logs) > 0 {
-			log.Printf("✅ Bootstrap penceresi (%d-%d): %d event", fromBlock.Int64(), toBlock.Int64(), len(logs))
-			for _, lg := range logs {
-				title, body := formatEventMessage(lg)
-				// Bootstrap sırasında bildirimleri doğrudan göndermek yerine buffer'a koy
-				if os.Getenv("BOOTSTRAP_NOTIFY") != "false" {
-					notificationBuffer <- notificationItem{title: title, body: body, time: time.Now()}
-				}
-			}
-		}
-
-		processed := int(toBlock.Int64()-fromBlock.Int64()) + 1 // dahil aralik
-		if processed <= 0 {
-			break
-		}
-		remaining -= processed
-		cursor += int64(processed)
+	sent := deliverNotification(title, body.String())
+	for _, item := range notifications {
+		// Gruplu mesajda retraction, ilgili öğenin başlığıyla aynı mesaja yanıt olarak gider
+		itemSent := sent
+		itemSent.title = item.title
+		afterSent(item, itemSent)
 	}
 }
 
-func subscribeWithReconnect(client *ethclient.Client) {
-	query := ethereum.FilterQuery{Addresses: WatchAddresses}
-	backoff := time.Second
-	maxBackoff := 30 * time.Second
-
-	trySubscribe := func() (ethereum.Subscription, chan types.Log, error) {
-		logsCh := make(chan types.Log)
-		sub, err := client.SubscribeFilterLogs(context.Background(), query, logsCh)
-		return sub, logsCh, err
-	}
-
-	for {
-		sub, logsCh, err := trySubscribe()
-		if err != nil {
-			msg := strings.ToLower(err.Error())
-			if strings.Contains(msg, "notifications not supported") || strings.Contains(msg, "websocket") {
-				log.Println("ℹ️ Subscribe desteklenmiyor, HTTP polling moduna geçiliyor...")
-				pollLogs(context.Background(), client)
-				time.Sleep(2 * time.Second)
-				continue
-			}
-			log.Printf("❌ Subscribe hatası: %v", err)
-			time.Sleep(backoff)
-			backoff *= 2
-			if backoff > maxBackoff {
-				backoff = maxBackoff
-			}
-			continue
-		}
-
-		log.Println("🔍 Event dinleme başlatıldı...")
-		backoff = time.Second
-
-		for {
-			select {
-			case err := <-sub.Err():
-				log.Printf("⛔️ Dinleme hatası: %v", err)
-				goto reconnect
-			case vLog := <-logsCh:
-				handleLiveEvent(vLog)
-			}
-		}
-
-	reconnect:
-		log.Println("↪️ Yeniden bağlanılıyor...")
-	}
+// afterSent gönderilen bildirimi reorg ve onay takibine bağlar
+func afterSent(item notificationItem, sent sentRef) {
+	markSent(item.refs, sent)
+	trackUnconfirmed(item, sent)
 }
 
 // İzlenen adresler için topics alanında kullanılacak 32-byte adres hash listesi
-func buildWatchedAddressTopics() []common.Hash {
-	topics := make([]common.Hash, 0, len(WatchAddresses))
-	for _, a := range WatchAddresses {
+func buildWatchedAddressTopics(ch *chainInstance) []common.Hash {
+	addrs := ch.addresses()
+	topics := make([]common.Hash, 0, len(addrs))
+	for _, a := range addrs {
 		// topic alanları 32 byte; adresleri soldan sıfır ile pad edilmiş biçimde hash'e koyarız
 		padded := common.LeftPadBytes(a.Bytes(), 32)
 		topics = append(topics, common.BytesToHash(padded))
@@ -1440,537 +1240,162 @@ func buildWatchedAddressTopics() []common.Hash {
 	return topics
 }
 
-// ERC20 Transfer eventlerini dinler: topicIndex=1 (from) veya 2 (to) izlenen adreslerden biri
-func subscribeTransferSideWithReconnect(client *ethclient.Client, topicIndex int) {
-	if topicIndex != 1 && topicIndex != 2 {
-		topicIndex = 1
-	}
-	watchedTopics := buildWatchedAddressTopics()
-	buildQuery := func() ethereum.FilterQuery {
-		// Topics: [transferTopic, from?, to?]; AND semantiği, aynı pozisyonda OR
-		topics := make([][]common.Hash, 3)
-		topics[0] = []common.Hash{transferTopic}
-		if topicIndex == 1 {
-			topics[1] = watchedTopics
-			topics[2] = nil
-		} else {
-			topics[1] = nil
-			topics[2] = watchedTopics
-		}
-		return ethereum.FilterQuery{Topics: topics}
-	}
-
-	backoff := time.Second
-	maxBackoff := 30 * time.Second
-
-	trySubscribe := func() (ethereum.Subscription, chan types.Log, error) {
-		logsCh := make(chan types.Log)
-		sub, err := client.SubscribeFilterLogs(context.Background(), buildQuery(), logsCh)
-		return sub, logsCh, err
-	}
+// StartEventListener tüm zincirlerin pipeline'larını çalıştırır ve ctx iptal edilip
+// hepsi durana kadar bloklar. Dönüşte RPC bağlantıları kapatılmış olur.
+func StartEventListener(ctx context.Context) {
+	chains := activeChains()
 
-	for {
-		sub, logsCh, err := trySubscribe()
-		if err != nil {
-			msg := strings.ToLower(err.Error())
-			if strings.Contains(msg, "notifications not supported") || strings.Contains(msg, "websocket") || strings.Contains(msg, "invalid logs options") {
-				log.Println("ℹ️ Transfer subscribe desteklenmiyor, HTTP polling moduna geçiliyor...")
-				pollTransfers(context.Background(), client, topicIndex)
-				time.Sleep(2 * time.Second)
-				continue
-			}
-			log.Printf("❌ Transfer subscribe hatası: %v", err)
-			time.Sleep(backoff)
-			backoff *= 2
-			if backoff > maxBackoff {
-				backoff = maxBackoff
-			}
-			continue
-		}
-
-		log.Printf("🔍 Transfer dinleme başlatıldı (topicIndex=%d)...", topicIndex)
-		backoff = time.Second
-
-		for {
-			select {
-			case err := <-sub.Err():
-				log.Printf("⛔️ Transfer dinleme hatası: %v", err)
-				goto reconnect
-			case vLog := <-logsCh:
-				handleLiveEvent(vLog)
-			}
-		}
+	// Fiyat cache'ini temizle (güvenlik düzeltmeleri için)
+	ClearAllTokenPriceCache()
+	log.Println("🔒 Güvenlik kontrolleri aktif - 1inch API devre dışı")
+	log.Println("🔄 Cache temizlendi, yeni fiyat hesaplama sistemi aktif")
 
-	reconnect:
-		log.Println("↪️ Transfer dinleyici yeniden bağlanıyor...")
+	// Event imzalarını (gömülü + SIGNATURES_DIR) ve ABI'leri yükle
+	if _, err := ReloadSignatures(); err != nil {
+		log.Printf("⚠️ İmza yükleme hatası: %v", err)
 	}
-}
-
-// HTTP polling ile transferleri tarar (topicIndex=1: from, 2: to)
-func pollTransfers(ctx context.Context, client *ethclient.Client, topicIndex int) {
-	// Optimize edilmiş transfer polling: 5 saniye aralık, 100-300 blok aralığı
-	interval := 5 * time.Second
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
 
-	head, err := client.BlockNumber(ctx)
-	if err != nil {
-		log.Printf("⚠️ Transfer polling başlangıç head alınamadı: %v", err)
-		head = 0
-	}
-	last := head
-
-	buildQuery := func(from, to *big.Int) ethereum.FilterQuery {
-		watchedTopics := buildWatchedAddressTopics()
-		topics := make([][]common.Hash, 3)
-		topics[0] = []common.Hash{transferTopic}
-		if topicIndex == 1 {
-			topics[1] = watchedTopics
-			topics[2] = nil
-		} else {
-			topics[1] = nil
-			topics[2] = watchedTopics
-		}
-		return ethereum.FilterQuery{FromBlock: from, ToBlock: to, Topics: topics}
+	// Aktif profil bilgisini göster
+	profile := strings.ToLower(strings.TrimSpace(os.Getenv("WALLET_PROFILE")))
+	if profile == "test" {
+		log.Printf("🧪 TEST PROFİLİ AKTİF")
+	} else {
+		log.Printf("🚀 PRODUCTION PROFİLİ AKTİF")
 	}
 
-	log.Printf("🧭 Optimize edilmiş transfer polling başlatıldı. Başlangıç head=%d, aralık=%s, blok aralığı=100-300 (topicIndex=%d)", last, interval, topicIndex)
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("🛑 Transfer polling durduruldu")
-			return
-		case <-ticker.C:
-			cur, err := client.BlockNumber(ctx)
-			if err != nil {
-				log.Printf("⚠️ Transfer polling head alınamadı: %v", err)
-				continue
-			}
-			if cur <= last {
-				continue
-			}
-
-			// Blok aralığını hesapla (100-300 arası)
-			blockRange := cur - last
-			if blockRange > 300 {
-				blockRange = 300 // Maksimum 300 blok
-			} else if blockRange < 100 {
-				// Eğer 100'den az blok varsa, biraz daha bekle
-				continue
-			}
-
-			from := big.NewInt(int64(last + 1))
-			to := big.NewInt(int64(last + blockRange))
-
-			log.Printf("🔍 Transfer blok aralığı taranıyor: %d-%d (%d blok, topicIndex=%d)", from.Int64(), to.Int64(), blockRange, topicIndex)
-
-			q := buildQuery(from, to)
-			logs, err := client.FilterLogs(ctx, q)
-			if err != nil {
-				log.Printf("⚠️ Transfer polling log hatası (%d-%d): %v", from.Int64(), to.Int64(), err)
-				continue
-			}
-
-			if len(logs) > 0 {
-				log.Printf("📊 %d transfer event bulundu (%d-%d aralığında, topicIndex=%d)", len(logs), from.Int64(), to.Int64(), topicIndex)
-			}
-
-			for _, lg := range logs {
-				handleLiveEvent(lg)
-			}
-			last = last + blockRange
+	// Replay canlı dedup durumunu değiştirmez ve bildirim göndermez: ayrı, bellek içi dedup
+	// deposu kullanılır, bildirimler REPLAY_OUTPUT dosyasına (boşsa stdout) yazılır
+	replay := getEventSourceKind() == eventSourceReplay
+	if replay {
+		eventDedup = newMemoryDedup()
+		if err := openReplaySink(getReplayOutput()); err != nil {
+			log.Fatalf("❌ Replay çıktısı açılamadı: %v", err)
 		}
 	}
-}
-
-// HTTP RPC üzerinde subscribe desteklenmiyorsa, periyodik olarak yeni blok aralığını tarar
-func pollLogs(ctx context.Context, client *ethclient.Client) {
-	// Optimize edilmiş polling: 3-5 saniye aralık, 100-300 blok aralığı
-	interval := 4 * time.Second // 4 saniye aralık
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	head, err := client.BlockNumber(ctx)
-	if err != nil {
-		log.Printf("⚠️ Başlangıç blok alınamadı: %v", err)
-		head = 0
-	}
-	last := head
-	log.Printf("🧭 Optimize edilmiş polling başlatıldı. Başlangıç head=%d, aralık=%s, blok aralığı=100-300", last, interval)
-
-	for {
-		select {
-		case <-ctx.Done():
-			log.Println("🛑 Polling durduruldu")
-			return
-		case <-ticker.C:
-			cur, err := client.BlockNumber(ctx)
-			if err != nil {
-				log.Printf("⚠️ Head alınamadı: %v", err)
-				continue
-			}
-			if cur <= last {
-				continue
-			}
 
-			// Blok aralığını hesapla (100-300 arası)
-			blockRange := cur - last
-			if blockRange > 300 {
-				blockRange = 300 // Maksimum 300 blok
-			} else if blockRange < 100 {
-				// Eğer 100'den az blok varsa, biraz daha bekle
-				continue
-			}
-
-			from := big.NewInt(int64(last + 1))
-			to := big.NewInt(int64(last + blockRange))
-
-			log.Printf("🔍 Blok aralığı taranıyor: %d-%d (%d blok)", from.Int64(), to.Int64(), blockRange)
-
-			q := ethereum.FilterQuery{FromBlock: from, ToBlock: to, Addresses: WatchAddresses}
-			logs, err := client.FilterLogs(ctx, q)
-			if err != nil {
-				log.Printf("⚠️ Polling log hatası (%d-%d): %v", from.Int64(), to.Int64(), err)
-				continue
-			}
-
-			if len(logs) > 0 {
-				log.Printf("📊 %d event bulundu (%d-%d aralığında)", len(logs), from.Int64(), to.Int64())
-			}
+	// Tekrar önleme deposunu periyodik olarak diske yaz
+	startDedupPersister(ctx)
+	startTokenMetaPersister(ctx)
 
-			for _, lg := range logs {
-				handleLiveEvent(lg)
-			}
-			last = last + blockRange
+	// Her zincir kendi sağlayıcı havuzu, reorg izleyicisi ve blok pipeline'ı ile çalışır
+	var wg sync.WaitGroup
+	started := 0
+	for _, ch := range chains {
+		if len(ch.cfg.RPC) == 0 && !replay {
+			log.Printf("⚠️ [%s] RPC tanımlı değil, zincir atlandı", ch.label())
+			continue
 		}
-	}
+		started++
+		wg.Add(1)
+		go func(ch *chainInstance) {
+			defer wg.Done()
+			runChain(ctx, ch)
+		}(ch)
+	}
+	if started == 0 {
+		log.Fatal("❌ ARBITRUM_RPC / ARBITRUM_RPCS ortam değişkeni tanımlı değil")
+	}
+	// Tüm zincirler durana kadar blokla
+	wg.Wait()
+	closeRecorder()
+	log.Println("🛑 Event listener durduruldu")
 }
 
-func StartEventListener() {
-	rpcUrl := os.Getenv("ARBITRUM_RPC")
-	if rpcUrl == "" {
-		log.Fatal("❌ ARBITRUM_RPC ortam değişkeni tanımlı değil")
+// runChain zincirin sağlayıcı havuzunu, reorg izleyicisini ve blok pipeline'ını çalıştırır
+func runChain(ctx context.Context, ch *chainInstance) {
+	if getEventSourceKind() == eventSourceReplay {
+		runReplayChain(ctx, ch)
+		return
 	}
 
-	client, err := ethclient.Dial(rpcUrl)
+	// Sağlayıcı havuzu: bağlanamayan sağlayıcılar arka planda tekrar denenir, süreç sonlanmaz
+	pool := newRPCPool(ch.name(), ch.cfg.RPC, ch.cfg.RPC[0], ch.cfg.HTTPRPC)
+	pool.startHealthChecks(ctx)
+	ch.setPool(pool)
+	defer func() {
+		ch.setPool(nil)
+		pool.close()
+	}()
+	client, err := pool.client()
 	if err != nil {
-		log.Fatalf("❌ RPC bağlantısı kurulamadı: %v", err)
-	}
-	fmt.Println("✅ RPC bağlantısı kuruldu")
-
-	// Fiyat cache'ini temizle (güvenlik düzeltmeleri için)
-	ClearAllTokenPriceCache()
-	log.Println("🔒 Güvenlik kontrolleri aktif - 1inch API devre dışı")
-	log.Println("🔄 Cache temizlendi, yeni fiyat hesaplama sistemi aktif")
-
-	// Raw HTTP RPC client init (kalıcı, tx tiplerinden bağımsız)
-	rawURL := strings.TrimSpace(os.Getenv("ARBITRUM_HTTP_RPC"))
-	if rawURL == "" {
-		// varsa wss/ws'yi https'ye çevir
-		rawURL = strings.ReplaceAll(rpcUrl, "wss://", "https://")
-		rawURL = strings.ReplaceAll(rawURL, "ws://", "http://")
-	}
-	if c, err := rpc.Dial(rawURL); err == nil {
-		rawRPCClient = c
-		log.Printf("🔗 Raw HTTP RPC hazır: %s", rawURL)
+		log.Printf("⚠️ [%s] Hiçbir RPC sağlayıcısına bağlanılamadı (%d adet), tekrar denenecek", ch.label(), len(ch.cfg.RPC))
 	} else {
-		log.Printf("⚠️ Raw HTTP RPC kurulamadı: %v", err)
-	}
-
-	// Global event imzalarını yükle
-	initGlobalEvents()
-
-	// ABI'lerden event isimlerini yükle
-	if err := LoadABIs(); err != nil {
-		log.Printf("⚠️ ABI yükleme hatası: %v", err)
-	}
-
-	if id, err := client.ChainID(context.Background()); err == nil {
-		log.Printf("🌐 ChainID: %s", id.String())
-	}
-
-	// Aktif profil bilgisini göster
-	profile := strings.ToLower(strings.TrimSpace(os.Getenv("WALLET_PROFILE")))
-	if profile == "test" {
-		log.Printf("🧪 TEST PROFİLİ AKTİF")
-	} else {
-		log.Printf("🚀 PRODUCTION PROFİLİ AKTİF")
+		fmt.Printf("✅ [%s] RPC bağlantısı kuruldu (%d sağlayıcı)\n", ch.label(), len(ch.cfg.RPC))
+		if id, err := client.ChainID(ctx); err == nil {
+			log.Printf("🌐 [%s] ChainID: %s", ch.label(), id.String())
+			if ch.cfg.ChainID != 0 && ch.cfg.ChainID != id.Uint64() {
+				log.Printf("⚠️ [%s] Yapılandırılan chainId (%d) RPC'nin döndürdüğüyle uyuşmuyor (%s)", ch.label(), ch.cfg.ChainID, id.String())
+			}
+		}
 	}
 
+	addrs := ch.addresses()
 	var addrSamples []string
-	for i, a := range WatchAddresses {
+	for i, a := range addrs {
 		if i >= 5 {
 			break
 		}
 		addrSamples = append(addrSamples, a.Hex())
 	}
-	log.Printf("👀 İzlenen adres sayısı: %d (örnekler: %s)", len(WatchAddresses), strings.Join(addrSamples, ", "))
-
-	// Opsiyonel bootstrap taraması (env ile kontrol edilebilir)
-	if strings.ToLower(os.Getenv("BOOTSTRAP_ENABLE")) != "false" {
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-		defer cancel()
-		go bootstrapScanWindowed(ctx, client)
-	}
+	log.Printf("👀 [%s] İzlenen adres sayısı: %d (örnekler: %s)", ch.label(), len(addrs), strings.Join(addrSamples, ", "))
 
-	// Opsiyonel: belirli bir tx hash'i için tanılama
-	if diag := strings.TrimSpace(os.Getenv("DIAG_TX_HASH")); diag != "" {
+	// Opsiyonel: belirli bir tx hash'i için tanılama (varsayılan zincir)
+	if diag := strings.TrimSpace(os.Getenv("DIAG_TX_HASH")); diag != "" && ch.global {
 		go func() {
 			time.Sleep(500 * time.Millisecond)
-			diagnoseTxByHash(client, diag)
+			if client, err := pool.client(); err == nil {
+				diagnoseTxByHash(ch, client, diag)
+			}
 		}()
 	}
 
-	// Native ETH tarayıcıyı başlat
-	startNativeTxScanner(client)
+	// Reorg izleyicisi: bildirim üretmiş blokların hash'lerini periyodik kontrol eder
+	startReorgMonitor(ctx, ch.name(), newRPCSource(ch, pool))
 
-	// Canlı event dinleme
-	go subscribeWithReconnect(client)
-	// ERC20 transferleri için hem from hem to tarafını ayrı dinle
-	go subscribeTransferSideWithReconnect(client, 1)
-	go subscribeTransferSideWithReconnect(client, 2)
-	// Ana goroutine'i blokla
-	select {}
-}
+	// Proxy izleyicisi: EIP-1967 slotlarındaki eventsiz değişiklikleri yakalar
+	startProxyMonitor(ctx, ch, pool)
 
-// removed parallel backfill
-// startNativeTxScanner: Yeni blokları tarayıp native ETH transferlerini (tx.Value>0) izler
-// Yalnızca bizim adreslerimiz (from/to) ile ilgili işlemleri bildirir
-func startNativeTxScanner(client *ethclient.Client) {
-	go func() {
-		// Native ETH tarayıcısını da optimize et: 3 saniye aralık
-		ticker := time.NewTicker(3 * time.Second)
-		defer ticker.Stop()
-
-		ctx := context.Background()
-		var last uint64
-		// Eğer bazı RPC sağlayıcıları yeni tx tiplerini desteklemiyorsa, kalıcı olarak raw moda geçeriz
-		useRawOnly := false
-		loggedRawSwitch := false
-		// Başlangıç head + geri tarama
-		if head, err := client.BlockNumber(ctx); err == nil {
-			backfill := 0
-			if v := strings.TrimSpace(os.Getenv("NATIVE_BACKFILL_BLOCKS")); v != "" {
-				if n, err := strconv.Atoi(v); err == nil && n > 0 {
-					backfill = n
-				}
-			}
-			if backfill > 0 {
-				if head > uint64(backfill) {
-					last = head - uint64(backfill)
-				} else {
-					last = 0
-				}
-				log.Printf("🔙 Native backfill: son %d blok taranacak (başlangıç=%d)", backfill, last)
-				// backfill kaldırıldı
-			} else {
-				last = head
-			}
+	// Tek blok cursor'u: adres logları, transfer logları ve native işlemler aynı akışta
+	// (checkpoint yoksa opsiyonel bootstrap penceresiyle başlar). RECORD_FILE tanımlıysa
+	// kaynaktan dönen her şey replay formatında kaydedilir.
+	var src EventSource = newRPCSource(ch, pool)
+	if rec := getRecorder(); rec != nil {
+		src = &recordingSource{EventSource: src, chain: ch.name(), rec: rec}
+	}
+	var pipeline *blockPipeline
+	for {
+		p, err := newBlockPipeline(ctx, ch, src)
+		if err == nil {
+			pipeline = p
+			break
 		}
-
-		// Signer önbelleği
-		var cachedChainID *big.Int
-		var cachedSigner types.Signer
-		debug := strings.ToLower(os.Getenv("DEBUG_MODE")) == "true"
-
-		for range ticker.C {
-			head, err := client.BlockNumber(ctx)
-			if err != nil || head <= last {
-				if debug {
-					if err != nil {
-						log.Printf("[native] head alinamadi: %v", err)
-					} else {
-						log.Printf("[native] yeni blok yok: head=%d last=%d", head, last)
-					}
-				}
-				continue
-			}
-			for bnum := last + 1; bnum <= head; bnum++ {
-				var blk *types.Block
-				var gethErr error
-				if !useRawOnly {
-					blk, gethErr = client.BlockByNumber(ctx, new(big.Int).SetUint64(bnum))
-				}
-				if useRawOnly || gethErr != nil || blk == nil {
-					// Eğer geth decode hatası "transaction type not supported" ise kalıcı olarak raw moda geç
-					if gethErr != nil && strings.Contains(strings.ToLower(gethErr.Error()), "transaction type not supported") {
-						useRawOnly = true
-						if !loggedRawSwitch {
-							log.Printf("[native] Geth decode desteklemiyor (yeni tx tipi). Kalıcı olarak RAW moda geçiliyor…")
-							loggedRawSwitch = true
-						}
-					}
-					if rawRPCClient == nil {
-						// Raw client yoksa bu bloğu atla
-						continue
-					}
-					var rawBlock struct {
-						Transactions []struct {
-							Hash  string `json:"hash"`
-							From  string `json:"from"`
-							To    string `json:"to"`
-							Value string `json:"value"`
-						} `json:"transactions"`
-					}
-					if err := rawRPCClient.CallContext(ctx, &rawBlock, "eth_getBlockByNumber", fmt.Sprintf("0x%x", bnum), true); err != nil {
-						// Sessizce devam et (bazı sağlayıcılar bu endpointi kısıtlayabilir)
-						continue
-					}
-					for _, rtx := range rawBlock.Transactions {
-						val := new(big.Int)
-						if len(rtx.Value) > 2 && strings.HasPrefix(rtx.Value, "0x") {
-							if _, ok := val.SetString(rtx.Value[2:], 16); !ok {
-								continue
-							}
-						}
-						if val.Sign() <= 0 {
-							continue
-						}
-						fromAddr := strings.ToLower(rtx.From)
-						toAddr := strings.ToLower(rtx.To)
-						isToWatched := toAddr != "" && WatchMap[toAddr]
-						isFromWatched := WatchMap[fromAddr]
-						if !isToWatched && !isFromWatched {
-							continue
-						}
-						// De-dupe with mutex
-						txh := rtx.Hash
-						// removed: mutex no longer needed without parallel backfill
-						if ts, ok := nativeSeen[txh]; ok {
-							if time.Since(ts) < nativeSeenTTL {
-								continue
-							}
-						}
-						nativeSeen[txh] = time.Now()
-
-						valueEth := new(big.Float).Quo(new(big.Float).SetInt(val), new(big.Float).SetFloat64(1e18))
-						valStr := valueEth.Text('f', 6)
-						dir := ""
-						if isFromWatched && isToWatched {
-							dir = "internal"
-						} else if isFromWatched {
-							dir = "out"
-						} else {
-							dir = "in"
-						}
-						// USD hesapla
-						nativePrice := getNativeUSDPrice()
-						ethUSD := new(big.Float).Mul(new(big.Float).SetFloat64(nativePrice), new(big.Float).Quo(new(big.Float).SetInt(val), new(big.Float).SetFloat64(1e18)))
-						usdStr := func() string { f, _ := ethUSD.Float64(); return fmt.Sprintf("~$%.2f", f) }()
-						// Receipt ve efektif gas fiyatı ekle
-						rcpt, _ := client.TransactionReceipt(ctx, common.HexToHash(txh))
-						effGas := ""
-						status := ""
-						if rcpt != nil {
-							status = map[uint64]string{1: "success", 0: "reverted"}[rcpt.Status]
-							if rcpt.EffectiveGasPrice != nil {
-								gwei := new(big.Float).Quo(new(big.Float).SetInt(rcpt.EffectiveGasPrice), big.NewFloat(1e9))
-								effGas = gwei.Text('f', 2) + " gwei"
-							}
-						}
-						body := fmt.Sprintf("📋 **Tx:** `%s`\n📤 **From:** `%s`\n📥 **To:** `%s`\n💰 **Value:** `%s ETH`\n💵 **USD:** `%s`\n🏷️ **Dir:** `%s`\n📊 **Status:** `%s`\n⛽ **Gas:** `%s`\n⏰ **Zaman:** `%s`",
-							txh, fromAddr, toAddr, valStr, usdStr, dir, status, effGas, time.Now().Format("02.01.2006 15:04:05"))
-						// Emoji seçimi
-						imp := determineImportance("[ETH] Transfer (ETH)", body)
-						emoji := "🔵"
-						if imp {
-							emoji = "🔴"
-						}
-						title := emoji + " [ETH] Transfer (ETH)"
-						select {
-						case notificationBuffer <- notificationItem{title: title, body: body, time: time.Now()}:
-						default:
-							log.Println("⚠️ Bildirim buffer'ı dolu, native ETH bildirimi atlandı")
-						}
-					}
-					continue
-				}
-				// Iterate txs (Geth yolu)
-				for _, tx := range blk.Transactions() {
-					if tx.Value() == nil || tx.Value().Sign() <= 0 {
-						continue
-					}
-					toAddr := ""
-					if tx.To() != nil {
-						toAddr = strings.ToLower(tx.To().Hex())
-					}
-					if cachedSigner == nil {
-						cid, cidErr := client.ChainID(ctx)
-						if cidErr != nil {
-							continue
-						}
-						cachedChainID = cid
-						cachedSigner = types.LatestSignerForChainID(cachedChainID)
-					}
-					fromAddress, err := types.Sender(cachedSigner, tx)
-					if err != nil {
-						continue
-					}
-					fromAddr := strings.ToLower(fromAddress.Hex())
-					isToWatched := toAddr != "" && WatchMap[toAddr]
-					isFromWatched := WatchMap[fromAddr]
-					if !isToWatched && !isFromWatched {
-						continue
-					}
-					// De-dupe with mutex
-					txh := tx.Hash().Hex()
-					// removed: mutex no longer needed without parallel backfill
-					if ts, ok := nativeSeen[txh]; ok {
-						if time.Since(ts) < nativeSeenTTL {
-							continue
-						}
-					}
-					nativeSeen[txh] = time.Now()
-
-					valueEth := new(big.Float).Quo(new(big.Float).SetInt(tx.Value()), new(big.Float).SetFloat64(1e18))
-					valStr := valueEth.Text('f', 6)
-					dir := ""
-					if isFromWatched && isToWatched {
-						dir = "internal"
-					} else if isFromWatched {
-						dir = "out"
-					} else {
-						dir = "in"
-					}
-					// USD hesapla
-					nativePrice := getNativeUSDPrice()
-					ethUSD := new(big.Float).Mul(new(big.Float).SetFloat64(nativePrice), new(big.Float).Quo(new(big.Float).SetInt(tx.Value()), new(big.Float).SetFloat64(1e18)))
-					usdStr := func() string { f, _ := ethUSD.Float64(); return fmt.Sprintf("~$%.2f", f) }()
-					// Receipt ve efektif gas fiyatı ekle
-					rcpt, _ := client.TransactionReceipt(ctx, tx.Hash())
-					effGas := ""
-					status := ""
-					if rcpt != nil {
-						status = map[uint64]string{1: "success", 0: "reverted"}[rcpt.Status]
-						if rcpt.EffectiveGasPrice != nil {
-							gwei := new(big.Float).Quo(new(big.Float).SetInt(rcpt.EffectiveGasPrice), big.NewFloat(1e9))
-							effGas = gwei.Text('f', 2) + " gwei"
-						}
-					}
-					body := fmt.Sprintf("📋 **Tx:** `%s`\n📤 **From:** `%s`\n📥 **To:** `%s`\n💰 **Value:** `%s ETH`\n💵 **USD:** `%s`\n🏷️ **Dir:** `%s`\n📊 **Status:** `%s`\n⛽ **Gas:** `%s`\n⏰ **Zaman:** `%s`",
-						txh, fromAddr, toAddr, valStr, usdStr, dir, status, effGas, time.Now().Format("02.01.2006 15:04:05"))
-					// Emoji seçimi
-					imp := determineImportance("[ETH] Transfer (ETH)", body)
-					emoji := "🔵"
-					if imp {
-						emoji = "🔴"
-					}
-					title := emoji + " [ETH] Transfer (ETH)"
-					select {
-					case notificationBuffer <- notificationItem{title: title, body: body, time: time.Now()}:
-					default:
-						log.Println("⚠️ Bildirim buffer'ı dolu, native ETH bildirimi atlandı")
-					}
-				}
-			}
-			last = head
+		log.Printf("⚠️ [%s] Pipeline başlatılamadı, 5 saniye sonra tekrar denenecek: %v", ch.label(), err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
 		}
-	}()
+	}
+	pipeline.run(ctx)
+}
+
+// runReplayChain zincirin pipeline'ını REPLAY_FILE kaydından besler (RPC ve reorg izleyicisi olmadan)
+func runReplayChain(ctx context.Context, ch *chainInstance) {
+	src, err := loadReplaySource(getReplayFile(), ch)
+	if err != nil {
+		log.Printf("❌ [%s] Replay kaynağı yüklenemedi: %v", ch.label(), err)
+		return
+	}
+	pipeline, err := newBlockPipeline(ctx, ch, src)
+	if err != nil {
+		log.Printf("❌ [%s] Replay pipeline'ı başlatılamadı: %v", ch.label(), err)
+		return
+	}
+	pipeline.run(ctx)
 }
 
-// diagnoseTxByHash: Belirtilen işlem hash'i için native akış şartlarını adım adım kontrol eder
-func diagnoseTxByHash(client *ethclient.Client, txHash string) {
+// diagnoseTxByHash: Belirtilen işlem hash'i için çağrılan fonksiyonu ve native akış şartlarını adım adım kontrol eder
+func diagnoseTxByHash(ch *chainInstance, client *ethclient.Client, txHash string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
 	defer cancel()
 
@@ -1981,6 +1406,18 @@ func diagnoseTxByHash(client *ethclient.Client, txHash string) {
 		return
 	}
 
+	// Çağrı: fonksiyon ve argümanlar (ABI'ler + yerel selector veritabanı)
+	if tx.To() != nil {
+		if dc := decodeCalldata(ch, txCall{to: strings.ToLower(tx.To().Hex()), input: tx.Data()}); dc != nil {
+			log.Printf("[diag] çağrı: %s", dc.label())
+			for _, f := range dc.fields {
+				log.Printf("[diag]   %s = %s", f.name, f.value)
+			}
+		} else {
+			log.Printf("[diag] çağrı verisi yok (düz transfer)")
+		}
+	}
+
 	if tx.Value() == nil || tx.Value().Sign() <= 0 {
 		log.Printf("[diag] tx value<=0, native degil: %s", txHash)
 		return
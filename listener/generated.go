@@ -0,0 +1,121 @@
+package listener
+
+//go:generate go run ../cmd/eventgen -abis abis -out events_gen.go
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// generatedEvent listener/abis'ten üretilen (events_gen.go) tek event çözücüsü ve varsayılan biçimleyicisi
+type generatedEvent struct {
+	address common.Address
+	event   abi.Event
+	// values logu tipli struct'a çözüp parametre adı -> değer olarak döner
+	values func(lg types.Log) (map[string]interface{}, error)
+	// format bildirim başlığı ve gövdesini üretir
+	format func(ch *chainInstance, lg types.Log, txd txDetails) (string, string, bool)
+}
+
+var (
+	generatedEvents   = make(map[string]generatedEvent) // adres:topic0
+	generatedEventsMu sync.RWMutex
+)
+
+func generatedEventKey(addr common.Address, topic common.Hash) string {
+	return strings.ToLower(addr.Hex() + ":" + topic.Hex())
+}
+
+// registerGeneratedEvent üretilen çözücüyü pipeline'a kaydeder (events_gen.go init içinden çağrılır)
+func registerGeneratedEvent(h generatedEvent) {
+	generatedEventsMu.Lock()
+	defer generatedEventsMu.Unlock()
+	generatedEvents[generatedEventKey(h.address, h.event.ID)] = h
+}
+
+// lookupGeneratedEvent logu üreten kontrat ve topic0 için üretilmiş çözücüyü döner
+func lookupGeneratedEvent(lg types.Log) (generatedEvent, bool) {
+	if len(lg.Topics) == 0 {
+		return generatedEvent{}, false
+	}
+	generatedEventsMu.RLock()
+	defer generatedEventsMu.RUnlock()
+	h, ok := generatedEvents[generatedEventKey(lg.Address, lg.Topics[0])]
+	return h, ok
+}
+
+// mustParseGeneratedEvent üretilen koddaki tek eventlik ABI parçasını çözer
+func mustParseGeneratedEvent(fragment string) abi.Event {
+	parsed, err := abi.JSON(strings.NewReader(fragment))
+	if err != nil {
+		panic(fmt.Sprintf("üretilen event ABI'si çözülemedi: %v", err))
+	}
+	for _, ev := range parsed.Events {
+		return ev
+	}
+	panic("üretilen ABI parçasında event yok")
+}
+
+// unpackEventLog indexed topic'leri ve data alanını parametre adı -> değer olarak çözer
+func unpackEventLog(ev abi.Event, lg types.Log) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(ev.Inputs))
+	var indexed abi.Arguments
+	for _, in := range ev.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	if len(indexed) != len(lg.Topics)-1 {
+		return nil, fmt.Errorf("%s: %d indexed parametre beklenirken %d topic var", ev.Name, len(indexed), len(lg.Topics)-1)
+	}
+	if len(indexed) > 0 {
+		if err := abi.ParseTopicsIntoMap(values, indexed, lg.Topics[1:]); err != nil {
+			return nil, err
+		}
+	}
+	if len(lg.Data) > 0 || len(ev.Inputs.NonIndexed()) > 0 {
+		if err := ev.Inputs.UnpackIntoMap(values, lg.Data); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// formatGeneratedLog üretilmiş çözücü varsa logun bildirimini onunla üretir
+func formatGeneratedLog(ch *chainInstance, lg types.Log, txd txDetails) (string, string, bool) {
+	h, ok := lookupGeneratedEvent(lg)
+	if !ok {
+		return "", "", false
+	}
+	return h.format(ch, lg, txd)
+}
+
+// generatedEventFields üretilmiş çözücü varsa logun alanlarını döner (tx bildirimi için)
+func generatedEventFields(ch *chainInstance, lg types.Log) ([]decodedField, bool) {
+	h, ok := lookupGeneratedEvent(lg)
+	if !ok {
+		return nil, false
+	}
+	values, err := h.values(lg)
+	if err != nil {
+		return nil, false
+	}
+	return formatDecodedArgs(ch, h.event.Inputs, values, lg.Address), true
+}
+
+// renderGeneratedEvent üretilen eventlerin varsayılan bildirimi: event adı, etiketli ve ölçeklenmiş alanlar
+func renderGeneratedEvent(ch *chainInstance, lg types.Log, ev abi.Event, values map[string]interface{}, txd txDetails) (string, string, bool) {
+	fields := renderDecodedFields(formatDecodedArgs(ch, ev.Inputs, values, lg.Address))
+	label := "[" + ch.category(lg.Address) + "] " + ev.RawName
+	body := fmt.Sprintf("📋 **Tx:** `%s`\n%s%s", lg.TxHash.Hex(), fields, txd.footer())
+	emoji := "🔵"
+	if determineImportance(label, body) {
+		emoji = "🔴"
+	}
+	return emoji + " " + label, body, true
+}
@@ -0,0 +1,129 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// adaptiveLogFetcher eth_getLogs isteklerini sağlayıcı limitlerine göre böler.
+// Limit hatası alan pencere ikiye bölünür, başarılı pencereler büyütülür; hiçbir blok atlanmaz.
+type adaptiveLogFetcher struct {
+	name      string
+	window    uint64
+	maxWindow uint64
+	successes int
+}
+
+// logFilterer fetch'in kullandığı eth_getLogs çağrısı (*ethclient.Client karşılar)
+type logFilterer interface {
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Pencerenin büyütülmesi için gereken ardışık başarılı istek sayısı
+const logFetchGrowAfter = 3
+
+// getLogsCallTimeout tek eth_getLogs isteği için zaman aşımını döner (LOGS_CALL_TIMEOUT, saniye)
+func getLogsCallTimeout() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("LOGS_CALL_TIMEOUT")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 20 * time.Second
+}
+
+func newAdaptiveLogFetcher(name string, maxWindow uint64) *adaptiveLogFetcher {
+	if maxWindow == 0 {
+		maxWindow = 1
+	}
+	return &adaptiveLogFetcher{name: name, window: maxWindow, maxWindow: maxWindow}
+}
+
+// isRangeLimitError sağlayıcının sonuç/aralık limiti ya da zaman aşımı hatası olup olmadığını döner
+func isRangeLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	// Hız limiti aralıkla ilgili değildir; bölmek yerine tekrar denenmeli
+	if strings.Contains(msg, "rate limit") || strings.Contains(msg, "429") {
+		return false
+	}
+	for _, s := range []string{
+		"more than",       // "query returned more than 10000 results"
+		"too many",        // "too many results", "too many logs"
+		"range too large", // "block range too large"
+		"range is too large",
+		"exceed", // "exceeds max results", "block range exceeded"
+		"limit",  // "response size limit", "query limit"
+		"timeout",
+		"timed out",
+		"response too large",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch [from, to] aralığındaki logları tamamı kapsanana kadar çeker.
+// Tek bloğa inildiği halde limit hatası sürerse ya da başka bir hata olursa hata döner;
+// çağıran aralığı (cursor ilerlemeden) daha sonra tekrar dener.
+func (f *adaptiveLogFetcher) fetch(ctx context.Context, client logFilterer, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	var all []types.Log
+	cur := from
+	for cur <= to {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		end := cur + f.window - 1
+		if end > to || end < cur {
+			end = to
+		}
+
+		wq := q
+		wq.FromBlock = new(big.Int).SetUint64(cur)
+		wq.ToBlock = new(big.Int).SetUint64(end)
+		callCtx, cancel := context.WithTimeout(ctx, getLogsCallTimeout())
+		logs, err := client.FilterLogs(callCtx, wq)
+		cancel()
+
+		if err != nil {
+			if ctx.Err() == nil && isRangeLimitError(err) && end > cur {
+				f.window = (end - cur + 1) / 2
+				f.successes = 0
+				if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+					log.Printf("✂️ [%s] eth_getLogs penceresi küçültüldü: %d blok (%d-%d): %v", f.name, f.window, cur, end, err)
+				}
+				continue
+			}
+			return nil, fmt.Errorf("[%s] log sorgusu başarısız (%d-%d): %w", f.name, cur, end, err)
+		}
+
+		all = append(all, logs...)
+		cur = end + 1
+		f.successes++
+		if f.successes >= logFetchGrowAfter && f.window < f.maxWindow {
+			f.window *= 2
+			if f.window > f.maxWindow {
+				f.window = f.maxWindow
+			}
+			f.successes = 0
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,119 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeFilterer maxSpan bloktan geniş pencereleri limit hatasıyla reddeder, diğerlerinde her blok için bir log döner
+type fakeFilterer struct {
+	maxSpan uint64
+	err     error // limit dışındaki sabit hata
+	calls   [][2]uint64
+}
+
+func (f *fakeFilterer) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	from, to := q.FromBlock.Uint64(), q.ToBlock.Uint64()
+	f.calls = append(f.calls, [2]uint64{from, to})
+	if f.err != nil {
+		return nil, f.err
+	}
+	if to-from+1 > f.maxSpan {
+		return nil, errors.New("query returned more than 10000 results")
+	}
+	var out []types.Log
+	for bn := from; bn <= to; bn++ {
+		out = append(out, types.Log{BlockNumber: bn})
+	}
+	return out, nil
+}
+
+func TestAdaptiveLogFetcherSplitsAndCoversRange(t *testing.T) {
+	f := newAdaptiveLogFetcher("test", 8)
+	client := &fakeFilterer{maxSpan: 3}
+	logs, err := f.fetch(context.Background(), client, ethereum.FilterQuery{}, 1, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 20 {
+		t.Fatalf("%d log, beklenen 20", len(logs))
+	}
+	for i, lg := range logs {
+		if lg.BlockNumber != uint64(i+1) {
+			t.Fatalf("log %d blok %d: atlanan ya da tekrarlanan blok var", i, lg.BlockNumber)
+		}
+	}
+	// Limit hatası alan pencere ikiye bölünür: 8 → 4 → 2
+	for i, want := range [][2]uint64{{1, 8}, {1, 4}, {1, 2}} {
+		if client.calls[i] != want {
+			t.Errorf("istek %d %v, beklenen %v", i, client.calls[i], want)
+		}
+	}
+}
+
+func TestAdaptiveLogFetcherGrowsAfterSuccesses(t *testing.T) {
+	f := newAdaptiveLogFetcher("test", 16)
+	f.window = 2
+	client := &fakeFilterer{maxSpan: 100}
+	if _, err := f.fetch(context.Background(), client, ethereum.FilterQuery{}, 1, 6); err != nil {
+		t.Fatal(err)
+	}
+	// 3 başarılı istekten sonra pencere iki katına çıkar
+	if f.window != 4 {
+		t.Fatalf("pencere %d, beklenen 4", f.window)
+	}
+	if _, err := f.fetch(context.Background(), client, ethereum.FilterQuery{}, 7, 200); err != nil {
+		t.Fatal(err)
+	}
+	if f.window != 16 {
+		t.Fatalf("pencere %d, üst sınır 16'da kalmalı", f.window)
+	}
+}
+
+func TestAdaptiveLogFetcherErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client *fakeFilterer
+		calls  int
+	}{
+		// Tek bloğa inildiği halde limit hatası sürerse hata döner
+		{"tek blokta limit", &fakeFilterer{maxSpan: 0}, 4},
+		// Hız limiti aralıkla ilgili değildir: bölünmeden hata döner
+		{"rate limit bölünmez", &fakeFilterer{maxSpan: 100, err: errors.New("429 Too Many Requests: rate limit")}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAdaptiveLogFetcher("test", 8)
+			if _, err := f.fetch(context.Background(), tt.client, ethereum.FilterQuery{}, 1, 8); err == nil {
+				t.Fatal("hata bekleniyordu")
+			}
+			if len(tt.client.calls) != tt.calls {
+				t.Errorf("%d istek, beklenen %d", len(tt.client.calls), tt.calls)
+			}
+		})
+	}
+}
+
+func TestIsRangeLimitError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{context.DeadlineExceeded, true},
+		{errors.New("block range too large"), true},
+		{errors.New("exceeds max results 10000"), true},
+		{errors.New("429 too many requests"), false},
+		{errors.New("rate limit exceeded"), false},
+		{errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		if got := isRangeLimitError(tt.err); got != tt.want {
+			t.Errorf("isRangeLimitError(%v) = %v, beklenen %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package listener
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ERC-1155 transfer topic'leri. ERC-721 Transfer, ERC-20 ile aynı topic0'ı kullanır
+// (tokenId de indexed olduğu için 4 topic, data boş).
+var (
+	// TransferSingle(address indexed operator, address indexed from, address indexed to, uint256 id, uint256 value)
+	transferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	// TransferBatch(address indexed operator, address indexed from, address indexed to, uint256[] ids, uint256[] values)
+	transferBatchTopic = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+var (
+	erc1155SingleArgs = func() abi.Arguments {
+		u, _ := abi.NewType("uint256", "", nil)
+		return abi.Arguments{{Name: "id", Type: u}, {Name: "value", Type: u}}
+	}()
+	erc1155BatchArgs = func() abi.Arguments {
+		u, _ := abi.NewType("uint256[]", "", nil)
+		return abi.Arguments{{Name: "ids", Type: u}, {Name: "values", Type: u}}
+	}()
+)
+
+// Batch transferlerde mesajda listelenen token id sınırı
+const maxListedTokenIDs = 20
+
+// nftTransfer çözümlenmiş ERC-721 / ERC-1155 transferi
+type nftTransfer struct {
+	standard string // ERC721 | ERC1155
+	operator common.Address
+	from     common.Address
+	to       common.Address
+	ids      []*big.Int
+	amounts  []*big.Int // ERC-721'de her id için 1
+}
+
+// isNFTTransferLog logun NFT transferi olup olmadığını topic yapısına göre döner
+func isNFTTransferLog(lg types.Log) bool {
+	if len(lg.Topics) == 0 {
+		return false
+	}
+	switch lg.Topics[0] {
+	case transferTopic, transferSingleTopic, transferBatchTopic:
+		return len(lg.Topics) == 4
+	}
+	return false
+}
+
+// parseNFTTransfer ERC-721 Transfer, ERC-1155 TransferSingle ve TransferBatch loglarını çözer
+func parseNFTTransfer(lg types.Log) *nftTransfer {
+	if !isNFTTransferLog(lg) {
+		return nil
+	}
+	addr := func(i int) common.Address { return common.BytesToAddress(lg.Topics[i].Bytes()) }
+	switch lg.Topics[0] {
+	case transferTopic:
+		from, to := addr(1), addr(2)
+		return &nftTransfer{
+			standard: "ERC721", operator: from, from: from, to: to,
+			ids: []*big.Int{lg.Topics[3].Big()}, amounts: []*big.Int{big.NewInt(1)},
+		}
+	case transferSingleTopic:
+		out, err := erc1155SingleArgs.Unpack(lg.Data)
+		if err != nil || len(out) != 2 {
+			return nil
+		}
+		id, _ := out[0].(*big.Int)
+		amount, _ := out[1].(*big.Int)
+		if id == nil || amount == nil {
+			return nil
+		}
+		return &nftTransfer{standard: "ERC1155", operator: addr(1), from: addr(2), to: addr(3), ids: []*big.Int{id}, amounts: []*big.Int{amount}}
+	case transferBatchTopic:
+		out, err := erc1155BatchArgs.Unpack(lg.Data)
+		if err != nil || len(out) != 2 {
+			return nil
+		}
+		ids, _ := out[0].([]*big.Int)
+		amounts, _ := out[1].([]*big.Int)
+		if len(ids) != len(amounts) {
+			return nil
+		}
+		return &nftTransfer{standard: "ERC1155", operator: addr(1), from: addr(2), to: addr(3), ids: ids, amounts: amounts}
+	}
+	return nil
+}
+
+// nftRelevant transferin bizi ilgilendirip ilgilendirmediğini döner: operator/from/to izleniyorsa
+// ya da koleksiyon bizim kontratımızsa (ör. grup NFT'leri)
+func nftRelevant(ch *chainInstance, lg types.Log, t *nftTransfer) bool {
+	for _, a := range []common.Address{t.operator, t.from, t.to, lg.Address} {
+		if ch.isWatched(strings.ToLower(a.Hex())) {
+			return true
+		}
+	}
+	return false
+}
+
+// kind transferin türünü döner (sıfır adresten mint, sıfır adrese burn)
+func (t *nftTransfer) kind() string {
+	switch {
+	case t.from == (common.Address{}):
+		return "mint"
+	case t.to == (common.Address{}):
+		return "burn"
+	}
+	return "transfer"
+}
+
+// tokenList token id'lerini (ERC-1155'te miktarla) sınırlı uzunlukta listeler
+func (t *nftTransfer) tokenList() string {
+	parts := make([]string, 0, len(t.ids))
+	for i, id := range t.ids {
+		if i >= maxListedTokenIDs {
+			parts = append(parts, fmt.Sprintf("+%d", len(t.ids)-i))
+			break
+		}
+		p := "#" + id.String()
+		if t.standard == "ERC1155" {
+			p += " ×" + t.amounts[i].String()
+		}
+		parts = append(parts, p)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// nftCollectionLabel koleksiyonu bilinen sembol ya da kategoriyle etiketler, yoksa kısaltılmış adres
+func nftCollectionLabel(ch *chainInstance, addr common.Address) string {
+	if sym := getAssetSymbol(ch, addr); sym != "" {
+		return sym
+	}
+	if ch.isWatched(strings.ToLower(addr.Hex())) {
+		return ch.category(addr)
+	}
+	return shortAddr(addr.Hex())
+}
+
+// formatNFTMessage NFT transferi için başlık ve gövde üretir; bizi ilgilendirmiyorsa boş döner
+func formatNFTMessage(ch *chainInstance, lg types.Log, txd txDetails) (string, string) {
+	t := parseNFTTransfer(lg)
+	if t == nil || !nftRelevant(ch, lg, t) {
+		return "", ""
+	}
+	collection := nftCollectionLabel(ch, lg.Address)
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("📋 **Tx:** `%s`\n", lg.TxHash.Hex()))
+	body.WriteString(fmt.Sprintf("🖼️ **Koleksiyon:** `%s` `%s`\n", collection, lg.Address.Hex()))
+	body.WriteString(fmt.Sprintf("📜 **Standart:** `%s` `%s`\n", t.standard, t.kind()))
+	if t.standard == "ERC1155" && t.operator != t.from {
+		body.WriteString(fmt.Sprintf("👷 **Operator:** `%s`\n", labelAddress(ch, t.operator)))
+	}
+	body.WriteString(fmt.Sprintf("📤 **From:** `%s`\n", labelAddress(ch, t.from)))
+	body.WriteString(fmt.Sprintf("📥 **To:** `%s`\n", labelAddress(ch, t.to)))
+	body.WriteString(fmt.Sprintf("🆔 **Token:** `%s`\n", t.tokenList()))
+	body.WriteString(txd.footer())
+
+	title := "🔵 [" + collection + "] NFT Transfer"
+	if determineImportance(title, body.String()) {
+		title = "🔴 [" + collection + "] NFT Transfer"
+	}
+	return title, body.String()
+}
+
+// nftBundleLine tx bildirimi için tek satırlık NFT transferi özeti ve net akışları
+func nftBundleLine(ch *chainInstance, lg types.Log, t *nftTransfer, flows *walletFlows) string {
+	collection := nftCollectionLabel(ch, lg.Address)
+	for i, id := range t.ids {
+		key := "nft:" + strings.ToLower(lg.Address.Hex()) + ":" + id.String()
+		flows.transfer(ch, strings.ToLower(t.from.Hex()), strings.ToLower(t.to.Hex()), key, collection+" #"+id.String(), 0, t.amounts[i])
+	}
+	return fmt.Sprintf("▫️ `#%d NFT %s %s` `%s` `%s` → `%s`", lg.Index, t.kind(), collection, t.tokenList(), shortAddr(t.from.Hex()), shortAddr(t.to.Hex()))
+}
@@ -0,0 +1,99 @@
+package listener
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func addrTopic(a common.Address) common.Hash { return common.BytesToHash(a.Bytes()) }
+
+func TestParseNFTTransfer(t *testing.T) {
+	operator := common.HexToAddress("0x00000000000000000000000000000000000000d4")
+	single, err := erc1155SingleArgs.Pack(big.NewInt(7), big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := erc1155BatchArgs.Pack([]*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(10), big.NewInt(20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mismatched, err := erc1155BatchArgs.Pack([]*big.Int{big.NewInt(1)}, []*big.Int{big.NewInt(10), big.NewInt(20)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		lg       types.Log
+		standard string
+		operator common.Address
+		ids      []int64
+		amounts  []int64
+		kind     string
+	}{
+		{
+			name:     "ERC-721 transfer",
+			lg:       types.Log{Topics: []common.Hash{transferTopic, addrTopic(testOther), addrTopic(testWallet), common.BigToHash(big.NewInt(42))}},
+			standard: "ERC721", operator: testOther, ids: []int64{42}, amounts: []int64{1}, kind: "transfer",
+		},
+		{
+			name:     "ERC-721 mint",
+			lg:       types.Log{Topics: []common.Hash{transferTopic, {}, addrTopic(testWallet), common.BigToHash(big.NewInt(1))}},
+			standard: "ERC721", operator: common.Address{}, ids: []int64{1}, amounts: []int64{1}, kind: "mint",
+		},
+		{
+			name:     "ERC-1155 TransferSingle",
+			lg:       types.Log{Topics: []common.Hash{transferSingleTopic, addrTopic(operator), addrTopic(testWallet), addrTopic(testOther)}, Data: single},
+			standard: "ERC1155", operator: operator, ids: []int64{7}, amounts: []int64{3}, kind: "transfer",
+		},
+		{
+			name:     "ERC-1155 TransferBatch burn",
+			lg:       types.Log{Topics: []common.Hash{transferBatchTopic, addrTopic(operator), addrTopic(testWallet), {}}, Data: batch},
+			standard: "ERC1155", operator: operator, ids: []int64{1, 2}, amounts: []int64{10, 20}, kind: "burn",
+		},
+		// ERC-20 Transfer (3 topic) NFT değildir
+		{name: "ERC-20 transfer", lg: types.Log{Topics: []common.Hash{transferTopic, addrTopic(testOther), addrTopic(testWallet)}}},
+		{name: "bozuk TransferSingle verisi", lg: types.Log{Topics: []common.Hash{transferSingleTopic, {}, {}, {}}, Data: []byte{1}}},
+		{name: "id ve miktar sayısı farklı", lg: types.Log{Topics: []common.Hash{transferBatchTopic, {}, {}, {}}, Data: mismatched}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseNFTTransfer(tt.lg)
+			if tt.standard == "" {
+				if got != nil {
+					t.Fatalf("nil bekleniyordu, %+v döndü", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("transfer çözülemedi")
+			}
+			if got.standard != tt.standard || got.operator != tt.operator || got.kind() != tt.kind {
+				t.Errorf("standart=%s operator=%s tür=%s", got.standard, got.operator.Hex(), got.kind())
+			}
+			if len(got.ids) != len(tt.ids) || len(got.amounts) != len(tt.amounts) {
+				t.Fatalf("ids=%v amounts=%v", got.ids, got.amounts)
+			}
+			for i := range tt.ids {
+				if got.ids[i].Int64() != tt.ids[i] || got.amounts[i].Int64() != tt.amounts[i] {
+					t.Errorf("%d: id=%v miktar=%v, beklenen %d ×%d", i, got.ids[i], got.amounts[i], tt.ids[i], tt.amounts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNFTTokenListLimit(t *testing.T) {
+	tr := &nftTransfer{standard: "ERC721"}
+	for i := 0; i < maxListedTokenIDs+5; i++ {
+		tr.ids = append(tr.ids, big.NewInt(int64(i)))
+		tr.amounts = append(tr.amounts, big.NewInt(1))
+	}
+	list := tr.tokenList()
+	if want := ", +5"; len(list) < len(want) || list[len(list)-len(want):] != want {
+		t.Fatalf("liste %q, %q ile bitmeli", list, want)
+	}
+}
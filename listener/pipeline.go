@@ -0,0 +1,528 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// nativeTransfer blok içindeki değer taşıyan (value>0) ve izlenen adresi ilgilendiren işlem
+// ya da (trace ile bulunan) kontrat çağrısı içi değer transferi
+type nativeTransfer struct {
+	txHash  common.Hash
+	txIndex uint
+	from    string // küçük harf
+	to      string // küçük harf, kontrat oluşturmada boş
+	value   *big.Int
+
+	internal   bool // kontrat çağrısı içi (trace) transfer
+	traceIndex int  // tx içindeki alt çağrının derinlik öncelikli sırası (internal için, tüm çağrılar sayılır)
+}
+
+// pipelineEvent birleştirilmiş akıştaki tek kayıt (log ya da native transfer)
+type pipelineEvent struct {
+	blockNumber uint64
+	blockHash   common.Hash
+	txIndex     uint
+	logIndex    int // native için -1: aynı tx'in loglarından önce gelir
+	lg          *types.Log
+	native      *nativeTransfer
+}
+
+// blockData bir blok için pipeline'ın ihtiyaç duyduğu özet
+type blockData struct {
+	hash       common.Hash
+	parentHash common.Hash
+	time       uint64 // blok zaman damgası (unix saniye; bilinmiyorsa 0)
+	natives    []nativeTransfer
+	txHashes   []common.Hash          // blok sırasıyla tüm tx'ler (trace sonuçlarını eşlemek için)
+	calls      map[common.Hash]txCall // izlenen adresleri ilgilendiren tx'lerin çağrı verisi
+}
+
+// Aralık işlenirken loglar ile blok hash'leri uyuşmazsa (aralık içinde reorg) dönen hata
+var errRangeReorg = errors.New("aralık işlenirken blok hash'i değişti")
+
+// blockPipeline tek bir blok cursor'u ile adres loglarını, transfer loglarını ve native
+// işlemleri aynı aralık için çeker, birleştirir ve kanonik sırada (blok, txIndex, logIndex) işler
+type blockPipeline struct {
+	chain      *chainInstance
+	checkpoint string // zincirin checkpoint anahtarı
+
+	// Veri kaynağı: canlı RPC havuzu ya da kayıt dosyası; cursor kaynaktan bağımsızdır
+	src EventSource
+
+	cursor     uint64 // tamamen işlenmiş son blok
+	cursorHash common.Hash
+
+	// BOOTSTRAP_NOTIFY=false iken bu bloğa kadar (dahil) bildirim üretilmez
+	quietUntil uint64
+
+	// İlk başarısız turun zamanı; bağlantı geri geldiğinde cursor'dan head'e kadar olan
+	// boşluk (kesinti süresince kaçırılan bloklar) atlanmadan işlenir
+	outageSince time.Time
+
+	// Replay: kayıt dosyasının sonuna ulaşıldığında bir kez loglanır
+	replay     bool
+	replayDone bool
+
+	// Backfill: tanımlıysa eventler bildirim yerine bu çıktıya yazılır (dedup/reorg takibi yok)
+	backfill *backfillWriter
+
+	// İşlenen aralıktaki tx çağrı verileri (bildirimlere çözümlenmiş fonksiyon ve argümanlar eklenir)
+	calls map[common.Hash]txCall
+
+	// İşlenen aralıktaki blok zamanları ve çekilmiş receipt'ler (tx başına tek istek)
+	blockTimes map[uint64]uint64
+	receipts   map[common.Hash]*types.Receipt
+}
+
+// getPipelineInterval head kontrol periyodunu döner (PIPELINE_POLL_INTERVAL, saniye)
+func getPipelineInterval() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("PIPELINE_POLL_INTERVAL")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 3 * time.Second
+}
+
+// getPipelineMaxRange tek seferde işlenecek en fazla blok sayısını döner (PIPELINE_MAX_RANGE)
+func getPipelineMaxRange() uint64 {
+	if v := strings.TrimSpace(os.Getenv("PIPELINE_MAX_RANGE")); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 100
+}
+
+// newBlockPipeline başlangıç cursor'unu belirler: checkpoint → eski pipeline checkpoint'leri → bootstrap → head.
+// Replay kaynağında her çalıştırma kayıt dosyasının ilk bloğundan başlar ve checkpoint yazılmaz.
+func newBlockPipeline(ctx context.Context, ch *chainInstance, src EventSource) (*blockPipeline, error) {
+	p := &blockPipeline{chain: ch, checkpoint: chainCheckpointName(ch.name()), src: src}
+	head, err := src.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+	observeHead(ch.name(), head)
+
+	if rs, ok := src.(*replaySource); ok {
+		p.replay = true
+		p.cursor = subFloor(rs.first, 1)
+		log.Printf("⏯️ [%s] Replay başlıyor: %d → %d", ch.label(), rs.first, head)
+		return p, nil
+	}
+
+	if cp, ok := getCheckpoint(p.checkpoint); ok {
+		cp = verifyCheckpointHash(ctx, src, p.checkpoint, cp)
+		p.cursor = cp.Block
+		p.cursorHash = common.HexToHash(cp.Hash)
+		log.Printf("⏩ [%s] Checkpoint'ten devam ediliyor: %d → %d (%d blok)", ch.label(), p.cursor+1, head, subFloor(head, p.cursor))
+		return p, nil
+	}
+
+	// Eski (ayrı watcher) checkpoint'lerinden en gerideki: hiçbir akışta boşluk kalmasın.
+	// Bu checkpoint'ler yalnızca varsayılan zincir için yazılmıştı.
+	var legacy uint64
+	found := false
+	for _, name := range []string{legacyPipelineLogs, legacyPipelineTransfersFrom, legacyPipelineTransfersTo, legacyPipelineNative} {
+		if !ch.global {
+			break
+		}
+		if cp, ok := getCheckpoint(name); ok && (!found || cp.Block < legacy) {
+			legacy = cp.Block
+			found = true
+		}
+	}
+	if found {
+		p.cursor = legacy
+		log.Printf("⏩ Eski pipeline checkpoint'lerinden devam ediliyor (blok=%d)", legacy)
+		return p, nil
+	}
+
+	// İlk çalıştırma: opsiyonel bootstrap penceresi
+	p.cursor = head
+	if strings.ToLower(os.Getenv("BOOTSTRAP_ENABLE")) != "false" {
+		total := uint64(2000)
+		if v := strings.TrimSpace(os.Getenv("BOOTSTRAP_BLOCKS")); v != "" {
+			if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+				total = n
+			}
+		}
+		p.cursor = subFloor(head, total)
+		if os.Getenv("BOOTSTRAP_NOTIFY") == "false" {
+			p.quietUntil = head
+		}
+		log.Printf("🔁 [%s] Bootstrap taraması: son %d blok (başlangıç=%d, bildirim=%v)", ch.label(), head-p.cursor, p.cursor+1, p.quietUntil == 0)
+	}
+	return p, nil
+}
+
+// getLogsMaxRange tek eth_getLogs isteğinin en fazla kaç blok kapsayacağını döner (LOGS_MAX_RANGE).
+// Sağlayıcı limit hatası verirse pencere otomatik olarak küçültülür.
+func getLogsMaxRange() uint64 {
+	if v := strings.TrimSpace(os.Getenv("LOGS_MAX_RANGE")); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return getPipelineMaxRange()
+}
+
+func subFloor(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return 0
+}
+
+// run head'i periyodik olarak izler ve cursor'u head'e kadar ilerletir. Hata olan aralık
+// atlanmaz; bir sonraki turda aynı yerden tekrar denenir.
+func (p *blockPipeline) run(ctx context.Context) {
+	interval := getPipelineInterval()
+	maxRange := getPipelineMaxRange()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("🧭 Blok pipeline'ı başlatıldı. Cursor=%d, aralık=%s, maksimum blok aralığı=%d", p.cursor, interval, maxRange)
+
+	for {
+		p.advance(ctx, maxRange)
+
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Blok pipeline'ı durduruldu")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// advance cursor'u head'e ulaşana ya da bir hata oluşana kadar ilerletir
+func (p *blockPipeline) advance(ctx context.Context, maxRange uint64) {
+	if bn, ok := consumeRewind(p.checkpoint); ok && bn <= p.cursor {
+		log.Printf("♻️ [%s] Pipeline reorg sonrası geri sarılıyor: %d → %d", p.chain.label(), p.cursor, bn-1)
+		p.cursor = bn - 1
+		p.cursorHash = common.Hash{}
+	}
+
+	head, err := p.src.BlockNumber(ctx)
+	if err != nil {
+		p.markOutage()
+		log.Printf("⚠️ Pipeline head alınamadı: %v", err)
+		return
+	}
+	observeHead(p.chain.name(), head)
+	if !p.outageSince.IsZero() {
+		log.Printf("🩹 Bağlantı geri geldi (kesinti: %s). Boşluk dolduruluyor: %d → %d (%d blok)",
+			time.Since(p.outageSince).Round(time.Second), p.cursor+1, head, subFloor(head, p.cursor))
+		p.outageSince = time.Time{}
+	}
+
+	for p.cursor < head {
+		if ctx.Err() != nil {
+			return
+		}
+		from := p.cursor + 1
+		to := from + maxRange - 1
+		if to > head {
+			to = head
+		}
+		if err := p.processRange(ctx, from, to); err != nil {
+			if ctx.Err() != nil {
+				// Kapanış: aralık checkpoint'e yazılmadı, yeniden başlatmada tekrar işlenir
+				return
+			}
+			if errors.Is(err, errRangeReorg) {
+				log.Printf("♻️ Aralık (%d-%d) işlenirken reorg tespit edildi, tekrar denenecek", from, to)
+				return
+			}
+			p.markOutage()
+			p.src.ReportFailure(err)
+			log.Printf("⚠️ Pipeline aralığı (%d-%d) başarısız, tekrar denenecek: %v", from, to, err)
+			return
+		}
+	}
+	if p.replay && !p.replayDone && p.cursor >= head {
+		p.replayDone = true
+		log.Printf("⏹️ [%s] Replay tamamlandı (son blok=%d)", p.chain.label(), p.cursor)
+	}
+}
+
+// markOutage kesintinin başladığı zamanı (ilk hata) kaydeder
+func (p *blockPipeline) markOutage() {
+	if p.outageSince.IsZero() {
+		p.outageSince = time.Now()
+	}
+}
+
+// processRange [from, to] aralığındaki tüm kaynakları çeker, birleştirir ve sırayla işler
+func (p *blockPipeline) processRange(ctx context.Context, from, to uint64) error {
+	// 1) Bloklar: hash zinciri ve native transferler
+	blocks := make(map[uint64]blockData, to-from+1)
+	for bn := from; bn <= to; bn++ {
+		bd, err := p.src.Block(ctx, bn)
+		if err != nil {
+			return fmt.Errorf("blok %d alınamadı: %w", bn, err)
+		}
+		blocks[bn] = bd
+	}
+
+	// Sınır kontrolü: ilk bloğun parent'ı, cursor bloğunun hash'i olmalı
+	if p.cursorHash != (common.Hash{}) && blocks[from].parentHash != (common.Hash{}) && blocks[from].parentHash != p.cursorHash {
+		log.Printf("♻️ Reorg tespit edildi: blok %d parent hash'i cursor ile uyuşmuyor", from)
+		checkReorgs(ctx, p.chain.name(), p.src)
+		depth := getReorgCheckDepth()
+		p.cursor = subFloor(p.cursor, depth)
+		p.cursorHash = common.Hash{}
+		return errRangeReorg
+	}
+
+	// 2) Loglar: adres bazlı + transfer (from/to) bazlı
+	logs, err := p.fetchLogs(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	for _, lg := range logs {
+		if bd, ok := blocks[lg.BlockNumber]; ok && bd.hash != (common.Hash{}) && bd.hash != lg.BlockHash {
+			return errRangeReorg
+		}
+	}
+
+	p.calls = make(map[common.Hash]txCall)
+	p.blockTimes = make(map[uint64]uint64, len(blocks))
+	p.receipts = make(map[common.Hash]*types.Receipt)
+	for bn, bd := range blocks {
+		for h, c := range bd.calls {
+			p.calls[h] = c
+		}
+		p.blockTimes[bn] = bd.time
+	}
+
+	// 3) Birleştir, tekilleştir ve kanonik sıraya koy
+	events := mergePipelineEvents(p.chain.name(), blocks, logs)
+	if len(events) > 0 {
+		log.Printf("📊 %d event bulundu (%d-%d aralığında)", len(events), from, to)
+	}
+
+	// 4) Sırayla işle: aynı tx'in kayıtları birlikte ele alınır (backfill'de bildirim yerine çıktı dosyasına)
+	for i := 0; i < len(events); {
+		j := i + 1
+		for j < len(events) && sameTx(events[i], events[j]) {
+			j++
+		}
+		group := events[i:j]
+		i = j
+		if p.quietUntil > 0 && group[0].blockNumber <= p.quietUntil {
+			continue
+		}
+		if p.backfill != nil {
+			for _, ev := range group {
+				if err := p.backfill.write(ctx, p, ev); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := p.emitTxGroup(ctx, group); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case p.backfill != nil:
+		if err := p.backfill.commit(to); err != nil {
+			return fmt.Errorf("backfill çıktısı yazılamadı: %w", err)
+		}
+	case !p.replay:
+		// Replay canlı checkpoint dosyasına yazmaz
+		saveCheckpoint(p.checkpoint, to, blocks[to].hash)
+	}
+	p.cursor = to
+	p.cursorHash = blocks[to].hash
+	return nil
+}
+
+// fetchLogs aralık için izlenen adreslerin loglarını ve izlenen adreslerin taraf olduğu transferleri çeker
+// (her sorgu kendi adaptif penceresiyle: transfer sorguları adres sorgusundan çok daha yoğun olabilir)
+func (p *blockPipeline) fetchLogs(ctx context.Context, from, to uint64) ([]types.Log, error) {
+	watchedTopics := buildWatchedAddressTopics(p.chain)
+	erc1155Topics := []common.Hash{transferSingleTopic, transferBatchTopic}
+	approvalTopics := []common.Hash{approvalTopic, approvalForAllTopic}
+
+	queries := []struct {
+		name string
+		q    ethereum.FilterQuery
+	}{
+		{"logs", ethereum.FilterQuery{Addresses: p.chain.addresses()}},
+		{"transfers_from", ethereum.FilterQuery{Topics: [][]common.Hash{{transferTopic}, watchedTopics}}},
+		{"transfers_to", ethereum.FilterQuery{Topics: [][]common.Hash{{transferTopic}, nil, watchedTopics}}},
+		// ERC-1155: operator, from ve to sırasıyla 1., 2. ve 3. topic'te (ERC-721 transfer sorgularına dahil)
+		{"nft1155_operator", ethereum.FilterQuery{Topics: [][]common.Hash{erc1155Topics, watchedTopics}}},
+		{"nft1155_from", ethereum.FilterQuery{Topics: [][]common.Hash{erc1155Topics, nil, watchedTopics}}},
+		{"nft1155_to", ethereum.FilterQuery{Topics: [][]common.Hash{erc1155Topics, nil, nil, watchedTopics}}},
+		// İzlenen cüzdanların verdiği onaylar (owner 1. topic'te)
+		{"approvals", ethereum.FilterQuery{Topics: [][]common.Hash{approvalTopics, watchedTopics}}},
+	}
+
+	var all []types.Log
+	for _, qq := range queries {
+		logs, err := p.src.Logs(ctx, qq.name, qq.q, from, to)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, logs...)
+	}
+	return all, nil
+}
+
+// mergePipelineEvents logları tekilleştirir, native transferlerle birleştirir ve
+// (blok, txIndex, logIndex) sırasına koyar
+func mergePipelineEvents(chain string, blocks map[uint64]blockData, logs []types.Log) []pipelineEvent {
+	seen := make(map[string]bool, len(logs))
+	events := make([]pipelineEvent, 0, len(logs))
+
+	for i := range logs {
+		lg := logs[i]
+		key := logRefOf(chain, lg).key
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		events = append(events, pipelineEvent{
+			blockNumber: lg.BlockNumber,
+			blockHash:   lg.BlockHash,
+			txIndex:     lg.TxIndex,
+			logIndex:    int(lg.Index),
+			lg:          &lg,
+		})
+	}
+
+	for bn, bd := range blocks {
+		for i := range bd.natives {
+			nt := bd.natives[i]
+			events = append(events, pipelineEvent{
+				blockNumber: bn,
+				blockHash:   bd.hash,
+				txIndex:     nt.txIndex,
+				logIndex:    -1,
+				native:      &nt,
+			})
+		}
+	}
+
+	// Aynı tx'in native kayıtları kaynaktan bağımsız olarak üst seviye, sonra trace sırasıyla internal dizilir
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if a.blockNumber != b.blockNumber {
+			return a.blockNumber < b.blockNumber
+		}
+		if a.txIndex != b.txIndex {
+			return a.txIndex < b.txIndex
+		}
+		if a.logIndex != b.logIndex {
+			return a.logIndex < b.logIndex
+		}
+		if a.native != nil && b.native != nil {
+			if a.native.internal != b.native.internal {
+				return !a.native.internal
+			}
+			return a.native.traceIndex < b.native.traceIndex
+		}
+		return false
+	})
+	return events
+}
+
+// emitLog tek logun bildirimini (tx çağrı bilgisiyle) kuyruğa ekler
+func (p *blockPipeline) emitLog(ctx context.Context, lg types.Log) error {
+	// Kaldırılan loglar reorg akışına gider, ilgisiz loglar atlanır
+	if lg.Removed || !isRelevantLog(p.chain, lg) {
+		return handleLiveEvent(ctx, p.chain, lg)
+	}
+	if eventDedup.seenAll([]logRef{logRefOf(p.chain.name(), lg)}) {
+		return nil
+	}
+	item, ok := p.logNotification(ctx, lg)
+	if !ok {
+		return nil
+	}
+	return enqueueNotification(ctx, item)
+}
+
+// logNotification logun bildirimini tx'in çözümlenmiş çağrı bilgisi, blok zamanı, durum ve gas bilgisiyle üretir
+func (p *blockPipeline) logNotification(ctx context.Context, lg types.Log) (notificationItem, bool) {
+	item, ok := logNotification(p.chain, lg, p.details(ctx, lg.TxHash, lg.BlockNumber))
+	if ok {
+		item.body = p.withCall(item.body, lg.TxHash)
+	}
+	return item, ok
+}
+
+// nativeRef native transferin kaydını döner
+func (p *blockPipeline) nativeRef(nt nativeTransfer, bnum uint64, blockHash common.Hash) logRef {
+	if nt.internal {
+		return internalRefOf(p.chain.name(), nt.txHash, nt.traceIndex, bnum, blockHash)
+	}
+	return nativeRefOf(p.chain.name(), nt.txHash, bnum, blockHash)
+}
+
+// emitNative native ETH transferi için bildirim üretip kuyruğa ekler
+func (p *blockPipeline) emitNative(ctx context.Context, nt nativeTransfer, bnum uint64, blockHash common.Hash) error {
+	// De-dupe: aynı tx için tekrar üretme (receipt çekmeden önce)
+	ref := p.nativeRef(nt, bnum, blockHash)
+	if eventDedup.seenAll([]logRef{ref}) {
+		return nil
+	}
+	return enqueueNotification(ctx, p.nativeNotification(ctx, nt, ref))
+}
+
+// nativeNotification native transfer için (receipt ve USD değeriyle) bildirim oluşturur
+func (p *blockPipeline) nativeNotification(ctx context.Context, nt nativeTransfer, ref logRef) notificationItem {
+	txh := nt.txHash.Hex()
+
+	isToWatched := nt.to != "" && p.chain.isWatched(nt.to)
+	isFromWatched := p.chain.isWatched(nt.from)
+
+	valueEth := new(big.Float).Quo(new(big.Float).SetInt(nt.value), new(big.Float).SetFloat64(1e18))
+	valStr := valueEth.Text('f', 6)
+	dir := ""
+	if isFromWatched && isToWatched {
+		dir = "internal"
+	} else if isFromWatched {
+		dir = "out"
+	} else {
+		dir = "in"
+	}
+	// USD hesapla
+	nativePrice := p.chain.nativeUSDPrice()
+	ethUSD := new(big.Float).Mul(new(big.Float).SetFloat64(nativePrice), valueEth)
+	usdStr := func() string { f, _ := ethUSD.Float64(); return fmt.Sprintf("~$%.2f", f) }()
+	sym := p.chain.cfg.NativeSymbol
+	body := fmt.Sprintf("📋 **Tx:** `%s`\n📤 **From:** `%s`\n📥 **To:** `%s`\n💰 **Value:** `%s %s`\n💵 **USD:** `%s`\n🏷️ **Dir:** `%s`\n%s",
+		txh, nt.from, nt.to, valStr, sym, usdStr, dir, p.details(ctx, nt.txHash, ref.blockNumber).footer())
+	if nt.internal {
+		body += "\n🔁 **Kaynak:** `kontrat çağrısı (internal)`"
+	}
+	body = p.withCall(body, nt.txHash)
+	// Emoji seçimi
+	label := "[" + sym + "] Transfer (" + sym + ")"
+	imp := determineImportance(label, body)
+	emoji := "🔵"
+	if imp {
+		emoji = "🔴"
+	}
+	title := p.chain.titled(emoji + " " + label)
+	return notificationItem{title: title, body: body, time: time.Now(), refs: []logRef{ref}}
+}
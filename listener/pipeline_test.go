@@ -0,0 +1,158 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	testWallet = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+	testOther  = common.HexToAddress("0x00000000000000000000000000000000000000b2")
+	testToken  = common.HexToAddress("0x00000000000000000000000000000000000000c3")
+)
+
+// testChain izleme listesinde yalnızca testWallet olan, RPC'siz zincir
+func testChain() *chainInstance {
+	return newChainInstance(chainConfig{
+		Name:    "testnet",
+		Wallets: map[string]string{testWallet.Hex(): "Test"},
+		Tokens:  map[string]chainToken{testToken.Hex(): {Symbol: "TST", Decimals: 6}},
+	})
+}
+
+// writeReplayFixture kayıtları JSONL olarak geçici dosyaya yazar
+func writeReplayFixture(t *testing.T, recs []sourceRecord) string {
+	t.Helper()
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	for _, r := range recs {
+		if err := enc.Encode(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "replay.jsonl")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testLog(bn uint64, blockHash, txHash common.Hash, txIndex, index uint) *types.Log {
+	return &types.Log{
+		Address:     testToken,
+		Topics:      []common.Hash{transferTopic, common.BytesToHash(testOther.Bytes()), common.BytesToHash(testWallet.Bytes())},
+		Data:        common.LeftPadBytes([]byte{1}, 32),
+		BlockNumber: bn,
+		TxHash:      txHash,
+		TxIndex:     txIndex,
+		BlockHash:   blockHash,
+		Index:       index,
+	}
+}
+
+func TestMergePipelineEventsReplayFixture(t *testing.T) {
+	ch := testChain()
+	h10, h11 := common.HexToHash("0x10"), common.HexToHash("0x11")
+	txA, txB, txC := common.HexToHash("0xa"), common.HexToHash("0xb"), common.HexToHash("0xc")
+	wallet := strings.ToLower(testWallet.Hex())
+	other := strings.ToLower(testOther.Hex())
+
+	path := writeReplayFixture(t, []sourceRecord{
+		{Type: recordBlock, Chain: "testnet", Block: &recordedBlock{Number: 10, Hash: h10}},
+		{Type: recordBlock, Chain: "testnet", Block: &recordedBlock{Number: 11, Hash: h11, ParentHash: h10}},
+		// Kayıt sırası bilerek karışık: sıralama merge'ün işi
+		{Type: recordLog, Chain: "testnet", Log: testLog(11, h11, txC, 1, 3)},
+		{Type: recordLog, Chain: "testnet", Log: testLog(10, h10, txB, 2, 5)},
+		{Type: recordLog, Chain: "testnet", Log: testLog(10, h10, txA, 0, 1)},
+		{Type: recordTx, Chain: "testnet", Tx: &recordedTx{Hash: txB, BlockNumber: 10, TxIndex: 2, From: other, To: wallet, Value: "7", Internal: true, TraceIndex: 1}},
+		{Type: recordTx, Chain: "testnet", Tx: &recordedTx{Hash: txB, BlockNumber: 10, TxIndex: 2, From: wallet, To: other, Value: "5"}},
+		// İzlenmeyen adresler arasındaki transfer Block'ta süzülür
+		{Type: recordTx, Chain: "testnet", Tx: &recordedTx{Hash: txC, BlockNumber: 11, TxIndex: 0, From: other, To: other, Value: "9"}},
+		// Başka zincirin kaydı okunmaz
+		{Type: recordLog, Chain: "othernet", Log: testLog(10, h10, txA, 0, 9)},
+	})
+	rs, err := loadReplaySource(path, ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rs.first != 10 || rs.last != 11 {
+		t.Fatalf("blok aralığı %d-%d, beklenen 10-11", rs.first, rs.last)
+	}
+
+	ctx := context.Background()
+	blocks := make(map[uint64]blockData)
+	for bn := uint64(10); bn <= 11; bn++ {
+		bd, err := rs.Block(ctx, bn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blocks[bn] = bd
+	}
+	// Adres ve transfer sorguları aynı logu iki kez döndürebilir
+	q := ethereum.FilterQuery{Addresses: []common.Address{testToken}}
+	first, _ := rs.Logs(ctx, "address", q, 10, 11)
+	second, _ := rs.Logs(ctx, "transfer", ethereum.FilterQuery{Topics: [][]common.Hash{{transferTopic}}}, 10, 11)
+	events := mergePipelineEvents("testnet", blocks, append(first, second...))
+
+	type want struct {
+		block    uint64
+		txIndex  uint
+		logIndex int
+		kind     string
+	}
+	wants := []want{
+		{10, 0, 1, "log"},
+		{10, 2, -1, "native"},
+		{10, 2, -1, "internal"},
+		{10, 2, 5, "log"},
+		{11, 1, 3, "log"},
+	}
+	if len(events) != len(wants) {
+		t.Fatalf("%d event, beklenen %d", len(events), len(wants))
+	}
+	for i, w := range wants {
+		ev := events[i]
+		kind := "log"
+		if ev.native != nil {
+			kind = "native"
+			if ev.native.internal {
+				kind = "internal"
+			}
+		}
+		if ev.blockNumber != w.block || ev.txIndex != w.txIndex || ev.logIndex != w.logIndex || kind != w.kind {
+			t.Errorf("event %d = {%d %d %d %s}, beklenen %+v", i, ev.blockNumber, ev.txIndex, ev.logIndex, kind, w)
+		}
+	}
+	if events[1].blockHash != h10 {
+		t.Errorf("native event blok hash'i %s, beklenen %s", events[1].blockHash.Hex(), h10.Hex())
+	}
+}
+
+func TestMatchesFilter(t *testing.T) {
+	lg := *testLog(1, common.Hash{}, common.Hash{}, 0, 0)
+	tests := []struct {
+		name string
+		q    ethereum.FilterQuery
+		want bool
+	}{
+		{"boş sorgu", ethereum.FilterQuery{}, true},
+		{"adres eşleşir", ethereum.FilterQuery{Addresses: []common.Address{testOther, testToken}}, true},
+		{"adres eşleşmez", ethereum.FilterQuery{Addresses: []common.Address{testOther}}, false},
+		{"topic joker", ethereum.FilterQuery{Topics: [][]common.Hash{nil, nil, {common.BytesToHash(testWallet.Bytes())}}}, true},
+		{"topic eşleşmez", ethereum.FilterQuery{Topics: [][]common.Hash{{approvalTopic}}}, false},
+		{"fazla topic", ethereum.FilterQuery{Topics: [][]common.Hash{nil, nil, nil, {transferTopic}}}, false},
+	}
+	for _, tt := range tests {
+		if got := matchesFilter(lg, tt.q); got != tt.want {
+			t.Errorf("%s: %v, beklenen %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// PriceQuote tek kaynaktan gelen fiyat ve hesaba katılıp katılmadığı
+type PriceQuote struct {
+	Source    string  `json:"source"`
+	Price     float64 `json:"price,omitempty"`
+	Liquidity float64 `json:"liquidityUsd,omitempty"` // DexScreener: hesaba katılan havuzların toplam likiditesi
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+	Outlier   bool    `json:"outlier,omitempty"`
+}
+
+// PriceProvider tek fiyat kaynağı. Fiyatı olmayan token için sıfır fiyatlı quote ve nil hata döner.
+type PriceProvider interface {
+	Name() string
+	Price(ctx context.Context, ch *chainInstance, addr string) (PriceQuote, error)
+}
+
+// PriceBreakdown son fiyat hesaplamasının kaynak dökümü (hata ayıklama için API'den okunur)
+type PriceBreakdown struct {
+	Chain  string       `json:"chain"`
+	Token  string       `json:"token"`
+	Price  float64      `json:"price"`
+	Median float64      `json:"median"`
+	Quotes []PriceQuote `json:"quotes"`
+	Note   string       `json:"note,omitempty"`
+	At     time.Time    `json:"at"`
+}
+
+// Kayıtlı fiyat sağlayıcıları (PRICE_PROVIDERS ile süzülür)
+var priceProviders = []PriceProvider{
+	dexScreenerProvider{},
+	coinGeckoProvider{},
+	onchainFeedProvider{},
+	staticPriceProvider{},
+}
+
+var (
+	priceBreakdowns   = make(map[string]PriceBreakdown) // anahtar: zincir:tokenAdresi
+	priceBreakdownsMu sync.Mutex
+)
+
+// getPriceProviders etkin sağlayıcıları döner (PRICE_PROVIDERS, virgülle ayrılmış; boşsa tümü).
+// Replay modunda fiyatlar yalnızca kayıt dosyasından gelir.
+func getPriceProviders() []PriceProvider {
+	if getEventSourceKind() == eventSourceReplay {
+		return []PriceProvider{replayPriceProvider{}}
+	}
+	v := strings.TrimSpace(os.Getenv("PRICE_PROVIDERS"))
+	if v == "" {
+		return priceProviders
+	}
+	enabled := make(map[string]bool)
+	for _, n := range strings.Split(v, ",") {
+		enabled[strings.ToLower(strings.TrimSpace(n))] = true
+	}
+	var out []PriceProvider
+	for _, p := range priceProviders {
+		if enabled[p.Name()] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// getPriceOutlierTolerance medyandan izin verilen sapma oranını döner (PRICE_OUTLIER_PCT, yüzde; varsayılan 20)
+func getPriceOutlierTolerance() float64 {
+	if v := strings.TrimSpace(os.Getenv("PRICE_OUTLIER_PCT")); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			return f / 100
+		}
+	}
+	return 0.20
+}
+
+// getPriceMinLiquidity DexScreener havuzlarının hesaba katılması için gereken likiditeyi döner (PRICE_MIN_LIQUIDITY_USD)
+func getPriceMinLiquidity() float64 {
+	if v := strings.TrimSpace(os.Getenv("PRICE_MIN_LIQUIDITY_USD")); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			return f
+		}
+	}
+	return 10000
+}
+
+// fetchFromMultipleSources tüm sağlayıcıları paralel sorgular, medyan alır ve aykırı fiyatları eler
+func fetchFromMultipleSources(ch *chainInstance, addr string) (float64, PriceBreakdown) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	providers := getPriceProviders()
+	quotes := make([]PriceQuote, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p PriceProvider) {
+			defer wg.Done()
+			q, err := p.Price(ctx, ch, addr)
+			q.Source = p.Name()
+			if err != nil {
+				q.Price = 0
+				q.Error = err.Error()
+			}
+			quotes[i] = q
+		}(i, p)
+	}
+	wg.Wait()
+
+	bd := PriceBreakdown{Chain: ch.name(), Token: addr, Quotes: quotes, At: time.Now()}
+	bd.Price, bd.Median, bd.Note = aggregatePrices(bd.Quotes)
+	recordPriceBreakdown(ch, bd)
+	if bd.Price > 0 {
+		log.Printf("🔍 Fiyat kaynakları: token=%s, %s → $%.4f", addr, quoteSummary(bd.Quotes), bd.Price)
+	} else {
+		log.Printf("⚠️ Hiçbir kaynaktan fiyat alınamadı: %s", addr)
+	}
+	return bd.Price, bd
+}
+
+// aggregatePrices geçerli quote'ların medyanını alır; medyandan tolerans dışında sapanları aykırı işaretler ve
+// kalanların medyanını döner. İki kaynak uyuşmuyorsa ya da tümü aykırıysa düşük fiyat seçilir:
+// şişirilmiş fiyat sahte önemli bildirim üretir, düşük fiyatın bedeli yalnızca kaçan bir yükseltmedir.
+func aggregatePrices(quotes []PriceQuote) (price, med float64, note string) {
+	var idx []int
+	for i, q := range quotes {
+		if q.Error == "" && q.Price > 0 && !math.IsInf(q.Price, 0) {
+			idx = append(idx, i)
+		}
+	}
+	if len(idx) == 0 {
+		return 0, 0, ""
+	}
+	values := make([]float64, len(idx))
+	for k, i := range idx {
+		values[k] = quotes[i].Price
+	}
+	med = median(values)
+	tol := getPriceOutlierTolerance()
+
+	var kept []float64
+	for _, i := range idx {
+		if math.Abs(quotes[i].Price-med)/med > tol {
+			quotes[i].Outlier = true
+			continue
+		}
+		kept = append(kept, quotes[i].Price)
+	}
+	if len(kept) > 0 {
+		return median(kept), med, ""
+	}
+
+	// Ortak fiyat yok: en düşük kaynak kullanılır, diğerleri aykırı kalır
+	low := idx[0]
+	for _, i := range idx[1:] {
+		if quotes[i].Price < quotes[low].Price {
+			low = i
+		}
+	}
+	quotes[low].Outlier = false
+	return quotes[low].Price, med, "kaynaklar uyuşmuyor, en düşük fiyat kullanıldı"
+}
+
+func median(values []float64) float64 {
+	s := append([]float64(nil), values...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// quoteSummary log satırı için kaynak özetini üretir
+func quoteSummary(quotes []PriceQuote) string {
+	var parts []string
+	for _, q := range quotes {
+		if q.Price <= 0 {
+			continue
+		}
+		s := fmt.Sprintf("%s=$%.4f", q.Source, q.Price)
+		if q.Outlier {
+			s += " (aykırı)"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// storePriceBreakdown son hesaplamanın dökümünü saklar
+func storePriceBreakdown(bd PriceBreakdown) {
+	priceBreakdownsMu.Lock()
+	priceBreakdowns[bd.Chain+":"+bd.Token] = bd
+	priceBreakdownsMu.Unlock()
+}
+
+// GetPriceBreakdown token fiyatının kaynak dökümünü döner. refresh ya da kayıtlı döküm yoksa fiyat yeniden hesaplanır.
+func GetPriceBreakdown(chain, token string, refresh bool) (*PriceBreakdown, error) {
+	ch, err := lookupChain(chain)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(token) {
+		return nil, fmt.Errorf("geçersiz token adresi: %s", token)
+	}
+	addr := strings.ToLower(common.HexToAddress(token).Hex())
+	key := priceCacheKey(ch, addr)
+
+	priceBreakdownsMu.Lock()
+	bd, ok := priceBreakdowns[key]
+	priceBreakdownsMu.Unlock()
+	if ok && !refresh {
+		return &bd, nil
+	}
+
+	tokenPriceMu.Lock()
+	delete(tokenPriceCache, key)
+	tokenPriceMu.Unlock()
+	fetchTokenUSDPrice(ch, common.HexToAddress(addr))
+
+	priceBreakdownsMu.Lock()
+	bd, ok = priceBreakdowns[key]
+	priceBreakdownsMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("fiyat hesaplanamadı: %s", addr)
+	}
+	return &bd, nil
+}
+
+// priceHTTPGet fiyat API'lerine ortak GET isteği (3 sn zaman aşımı, rate limit kontrolü)
+func priceHTTPGet(ctx context.Context, url string, out interface{}) error {
+	if isRateLimited() {
+		return fmt.Errorf("rate limit aktif")
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: 3 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// dexScreenerProvider token'ın base olduğu havuzların likidite ağırlıklı medyan fiyatı.
+// Likiditesi PRICE_MIN_LIQUIDITY_USD altındaki havuzlar fiyatı şişirmesin diye yok sayılır.
+type dexScreenerProvider struct{}
+
+func (dexScreenerProvider) Name() string { return "dexscreener" }
+
+func (dexScreenerProvider) Price(ctx context.Context, ch *chainInstance, addr string) (PriceQuote, error) {
+	var payload struct {
+		Pairs []struct {
+			ChainId   string `json:"chainId"`
+			DexId     string `json:"dexId"`
+			PriceUsd  string `json:"priceUsd"`
+			BaseToken struct {
+				Address string `json:"address"`
+			} `json:"baseToken"`
+			Liquidity struct {
+				Usd float64 `json:"usd"`
+			} `json:"liquidity"`
+		} `json:"pairs"`
+	}
+	if err := priceHTTPGet(ctx, "https://api.dexscreener.com/latest/dex/tokens/"+addr, &payload); err != nil {
+		return PriceQuote{}, err
+	}
+
+	type pairPrice struct {
+		price, liquidity float64
+	}
+	var pairs []pairPrice
+	var total float64
+	dexes := make(map[string]bool)
+	minLiq := getPriceMinLiquidity()
+	for _, p := range payload.Pairs {
+		// Aynı adres başka zincirlerde farklı bir token olabilir
+		if ch.cfg.DexScreenerChain != "" && !strings.EqualFold(p.ChainId, ch.cfg.DexScreenerChain) {
+			continue
+		}
+		// priceUsd havuzun base token'ının fiyatıdır; token quote tarafındaysa diğer tokenın fiyatı gelir
+		if !strings.EqualFold(p.BaseToken.Address, addr) || p.Liquidity.Usd < minLiq {
+			continue
+		}
+		f, err := strconv.ParseFloat(p.PriceUsd, 64)
+		if err != nil || f <= 0 {
+			continue
+		}
+		pairs = append(pairs, pairPrice{f, p.Liquidity.Usd})
+		total += p.Liquidity.Usd
+		dexes[p.DexId] = true
+	}
+	if len(pairs) == 0 || total <= 0 {
+		return PriceQuote{}, nil
+	}
+
+	// Ağırlıklı medyan: likiditenin yarısına ulaşılan havuzun fiyatı
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].price < pairs[j].price })
+	price := pairs[len(pairs)-1].price
+	var cum float64
+	for _, p := range pairs {
+		cum += p.liquidity
+		if cum >= total/2 {
+			price = p.price
+			break
+		}
+	}
+	names := make([]string, 0, len(dexes))
+	for d := range dexes {
+		names = append(names, d)
+	}
+	sort.Strings(names)
+	return PriceQuote{
+		Price:     price,
+		Liquidity: total,
+		Detail:    fmt.Sprintf("%d havuz (%s)", len(pairs), strings.Join(names, ", ")),
+	}, nil
+}
+
+// coinGeckoProvider zincirin platform id'si ile (örn. arbitrum-one) CoinGecko token fiyatı
+type coinGeckoProvider struct{}
+
+func (coinGeckoProvider) Name() string { return "coingecko" }
+
+func (coinGeckoProvider) Price(ctx context.Context, ch *chainInstance, addr string) (PriceQuote, error) {
+	if ch.cfg.CoinGeckoPlatform == "" {
+		return PriceQuote{}, nil
+	}
+	url := fmt.Sprintf("https://api.coingecko.com/api/v3/simple/token_price/%s?contract_addresses=%s&vs_currencies=usd", ch.cfg.CoinGeckoPlatform, addr)
+	var payload map[string]map[string]float64
+	if err := priceHTTPGet(ctx, url, &payload); err != nil {
+		return PriceQuote{}, err
+	}
+	return PriceQuote{Price: payload[addr]["usd"]}, nil
+}
+
+// Chainlink aggregator selector'ları
+var latestRoundDataSelector = []byte{0xfe, 0xaf, 0x96, 0x8c} // latestRoundData()
+
+// onchainFeedProvider token için tanımlı Chainlink USD feed'inden (priceFeeds / PRICE_FEEDS) fiyat okur
+type onchainFeedProvider struct{}
+
+func (onchainFeedProvider) Name() string { return "onchain" }
+
+func (onchainFeedProvider) Price(ctx context.Context, ch *chainInstance, addr string) (PriceQuote, error) {
+	feed, ok := configuredPrice(ch, ch.cfg.PriceFeeds, "PRICE_FEEDS", addr)
+	if !ok || !common.IsHexAddress(feed) {
+		return PriceQuote{}, nil
+	}
+	pool := ch.activePool()
+	if pool == nil {
+		return PriceQuote{}, fmt.Errorf("RPC bağlı değil")
+	}
+	client, err := pool.client()
+	if err != nil {
+		return PriceQuote{}, err
+	}
+	price, err := readChainlinkFeed(ctx, client, common.HexToAddress(feed))
+	if err != nil {
+		return PriceQuote{}, err
+	}
+	return PriceQuote{Price: price, Detail: "feed " + shortAddr(feed)}, nil
+}
+
+// readChainlinkFeed latestRoundData cevabını feed ondalığına göre ölçekler; bayat (1 günden eski) cevap hata sayılır
+func readChainlinkFeed(ctx context.Context, caller ethereum.ContractCaller, feed common.Address) (float64, error) {
+	out, err := caller.CallContract(ctx, ethereum.CallMsg{To: &feed, Data: latestRoundDataSelector}, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(out) < 160 {
+		return 0, fmt.Errorf("geçersiz latestRoundData cevabı")
+	}
+	answer := new(big.Int).SetBytes(out[32:64])
+	if answer.Sign() <= 0 || answer.Cmp(new(big.Int).Lsh(big.NewInt(1), 255)) >= 0 {
+		return 0, fmt.Errorf("geçersiz feed cevabı")
+	}
+	if updated := new(big.Int).SetBytes(out[96:128]).Int64(); time.Since(time.Unix(updated, 0)) > 24*time.Hour {
+		return 0, fmt.Errorf("feed bayat (%s)", time.Unix(updated, 0).Format("02.01.2006 15:04"))
+	}
+	decimals := 8
+	if d, err := caller.CallContract(ctx, ethereum.CallMsg{To: &feed, Data: decimalsSelector}, nil); err == nil && len(d) == 32 {
+		if n := new(big.Int).SetBytes(d); n.IsInt64() && n.Int64() <= 36 {
+			decimals = int(n.Int64())
+		}
+	}
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(answer), new(big.Float).SetFloat64(math.Pow10(decimals))).Float64()
+	return f, nil
+}
+
+// staticPriceProvider yapılandırmadaki sabit fiyat (staticPrices / STATIC_TOKEN_PRICES)
+type staticPriceProvider struct{}
+
+func (staticPriceProvider) Name() string { return "static" }
+
+func (staticPriceProvider) Price(_ context.Context, ch *chainInstance, addr string) (PriceQuote, error) {
+	for a, p := range ch.cfg.StaticPrices {
+		if strings.EqualFold(a, addr) && p > 0 {
+			return PriceQuote{Price: p}, nil
+		}
+	}
+	v, ok := configuredPrice(ch, nil, "STATIC_TOKEN_PRICES", addr)
+	if !ok {
+		return PriceQuote{}, nil
+	}
+	p, err := strconv.ParseFloat(v, 64)
+	if err != nil || p <= 0 {
+		return PriceQuote{}, fmt.Errorf("geçersiz sabit fiyat: %s", v)
+	}
+	return PriceQuote{Price: p}, nil
+}
+
+// configuredPrice token için zincir dosyasındaki değeri, varsayılan zincirde ise env listesini
+// (virgülle ayrılmış token=değer) döner
+func configuredPrice(ch *chainInstance, m map[string]string, env, addr string) (string, bool) {
+	for a, v := range m {
+		if strings.EqualFold(a, addr) {
+			return strings.TrimSpace(v), true
+		}
+	}
+	if !ch.global {
+		return "", false
+	}
+	for _, kv := range strings.Split(os.Getenv(env), ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), addr) {
+			return strings.TrimSpace(parts[1]), true
+		}
+	}
+	return "", false
+}
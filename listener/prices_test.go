@@ -0,0 +1,83 @@
+package listener
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAggregatePrices(t *testing.T) {
+	tests := []struct {
+		name     string
+		quotes   []PriceQuote
+		price    float64
+		median   float64
+		outliers []bool
+		note     bool
+	}{
+		{
+			name:   "kaynak yok",
+			quotes: nil,
+		},
+		{
+			name:     "hatalı ve sıfır fiyatlar sayılmaz",
+			quotes:   []PriceQuote{{Price: 2, Error: "timeout"}, {Price: 0}, {Price: 1.5}},
+			price:    1.5,
+			median:   1.5,
+			outliers: []bool{false, false, false},
+		},
+		{
+			name:     "sonsuz fiyat sayılmaz",
+			quotes:   []PriceQuote{{Price: math.Inf(1)}, {Price: 3}},
+			price:    3,
+			median:   3,
+			outliers: []bool{false, false},
+		},
+		{
+			name:     "uyumlu kaynakların medyanı",
+			quotes:   []PriceQuote{{Price: 100}, {Price: 102}, {Price: 98}},
+			price:    100,
+			median:   100,
+			outliers: []bool{false, false, false},
+		},
+		{
+			name:     "aykırı kaynak elenir",
+			quotes:   []PriceQuote{{Price: 100}, {Price: 101}, {Price: 500}},
+			price:    100.5,
+			median:   101,
+			outliers: []bool{false, false, true},
+		},
+		{
+			name:     "iki kaynak uyuşmuyorsa düşük fiyat",
+			quotes:   []PriceQuote{{Price: 10}, {Price: 40}},
+			price:    10,
+			median:   25,
+			outliers: []bool{false, true},
+			note:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, med, note := aggregatePrices(tt.quotes)
+			if price != tt.price || med != tt.median {
+				t.Fatalf("fiyat=%v medyan=%v, beklenen %v / %v", price, med, tt.price, tt.median)
+			}
+			if (note != "") != tt.note {
+				t.Errorf("not %q, not bekleniyor mu: %v", note, tt.note)
+			}
+			for i, want := range tt.outliers {
+				if tt.quotes[i].Outlier != want {
+					t.Errorf("quote %d aykırı=%v, beklenen %v", i, tt.quotes[i].Outlier, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAggregatePricesTolerance(t *testing.T) {
+	t.Setenv("PRICE_OUTLIER_PCT", "50")
+	quotes := []PriceQuote{{Price: 100}, {Price: 101}, {Price: 140}}
+	price, _, _ := aggregatePrices(quotes)
+	if price != 101 || quotes[2].Outlier {
+		t.Fatalf("fiyat=%v aykırı=%v, %%50 toleransta 140 elenmemeli", price, quotes[2].Outlier)
+	}
+}
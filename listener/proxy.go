@@ -0,0 +1,455 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// EIP-1967 proxy eventleri
+var (
+	// Upgraded(address indexed implementation)
+	upgradedTopic = crypto.Keccak256Hash([]byte("Upgraded(address)"))
+	// AdminChanged(address previousAdmin, address newAdmin)
+	adminChangedTopic = crypto.Keccak256Hash([]byte("AdminChanged(address,address)"))
+	// BeaconUpgraded(address indexed beacon)
+	beaconUpgradedTopic = crypto.Keccak256Hash([]byte("BeaconUpgraded(address)"))
+)
+
+// proxySlot EIP-1967 depolama slotu: keccak256(ad) - 1
+type proxySlot struct {
+	name string // implementation | admin | beacon
+	slot common.Hash
+}
+
+var proxySlots = func() []proxySlot {
+	slot := func(id string) common.Hash {
+		h := new(big.Int).SetBytes(crypto.Keccak256([]byte(id)))
+		return common.BigToHash(h.Sub(h, big.NewInt(1)))
+	}
+	return []proxySlot{
+		{"implementation", slot("eip1967.proxy.implementation")},
+		{"admin", slot("eip1967.proxy.admin")},
+		{"beacon", slot("eip1967.proxy.beacon")},
+	}
+}()
+
+// isProxyLog logun EIP-1967 proxy eventi olup olmadığını döner
+func isProxyLog(lg types.Log) bool {
+	if len(lg.Topics) == 0 {
+		return false
+	}
+	switch lg.Topics[0] {
+	case upgradedTopic, beaconUpgradedTopic:
+		return len(lg.Topics) == 2
+	case adminChangedTopic:
+		return len(lg.Topics) == 1 && len(lg.Data) >= 64
+	}
+	return false
+}
+
+// proxyChange proxy eventinden çözülen slot değişikliği (önceki değer yalnızca AdminChanged'de bilinir)
+type proxyChange struct {
+	event    string
+	slot     string
+	previous *common.Address
+	current  common.Address
+}
+
+// parseProxyLog Upgraded, AdminChanged ve BeaconUpgraded loglarını çözer
+func parseProxyLog(lg types.Log) *proxyChange {
+	if !isProxyLog(lg) {
+		return nil
+	}
+	switch lg.Topics[0] {
+	case upgradedTopic:
+		return &proxyChange{event: "Upgraded", slot: "implementation", current: common.BytesToAddress(lg.Topics[1].Bytes())}
+	case beaconUpgradedTopic:
+		return &proxyChange{event: "BeaconUpgraded", slot: "beacon", current: common.BytesToAddress(lg.Topics[1].Bytes())}
+	}
+	prev := common.BytesToAddress(lg.Data[:32])
+	return &proxyChange{event: "AdminChanged", slot: "admin", previous: &prev, current: common.BytesToAddress(lg.Data[32:64])}
+}
+
+// slotEmoji slot satırının simgesi
+func slotEmoji(slot string) string {
+	switch slot {
+	case "admin":
+		return "👑"
+	case "beacon":
+		return "🗼"
+	}
+	return "🧬"
+}
+
+// formatProxyMessage proxy eventi için her zaman önemli (🔴) başlık ve gövde üretir
+func formatProxyMessage(ch *chainInstance, lg types.Log, txd txDetails) (string, string) {
+	pc := parseProxyLog(lg)
+	if pc == nil {
+		return "", ""
+	}
+	// Önceki değer bilinen durumdan okunur; yeni değer bildirim kuyruğa girince kaydedilir (applyProxySlots)
+	if prev, known := lookupProxySlot(ch, lg.Address, pc.slot); pc.previous == nil && known && prev.Block <= lg.BlockNumber && prev.Value != pc.current {
+		pc.previous = &prev.Value
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("📋 **Tx:** `%s`\n", lg.TxHash.Hex()))
+	body.WriteString(fmt.Sprintf("🛡️ **Proxy:** `%s`\n", labelAddress(ch, lg.Address)))
+	change := labelAddress(ch, pc.current)
+	if pc.previous != nil {
+		change = labelAddress(ch, *pc.previous) + " → " + change
+	}
+	body.WriteString(fmt.Sprintf("%s **%s:** `%s`\n", slotEmoji(pc.slot), strings.ToUpper(pc.slot[:1])+pc.slot[1:], change))
+	body.WriteString(txd.footer())
+	return "🔴 [" + ch.category(lg.Address) + "] " + pc.event, body.String()
+}
+
+// proxyBundleLine tx bildirimi için tek satırlık proxy değişikliği özeti
+func proxyBundleLine(ch *chainInstance, lg types.Log, pc *proxyChange) string {
+	return fmt.Sprintf("▫️ `#%d %s` `%s` %s `%s`", lg.Index, pc.event, shortAddr(lg.Address.Hex()), slotEmoji(pc.slot), shortAddr(pc.current.Hex()))
+}
+
+// getProxyCheckInterval EIP-1967 slot kontrol periyodunu döner (PROXY_CHECK_INTERVAL, saniye; 0 kapatır)
+func getProxyCheckInterval() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("PROXY_CHECK_INTERVAL")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 60 * time.Second
+}
+
+// getProxyStateFile bilinen slot değerlerinin yazıldığı dosyayı döner (PROXY_STATE_FILE)
+func getProxyStateFile() string {
+	if v := strings.TrimSpace(os.Getenv("PROXY_STATE_FILE")); v != "" {
+		return v
+	}
+	return filepath.Join("data", "proxy_slots.json")
+}
+
+// proxyTargets slot kontrolü yapılacak adresler: izlenen adreslerden PROXY_ADDRESSES (virgülle ayrılmış)
+// listesindekiler, liste boşsa izlenen tüm adresler
+func proxyTargets(ch *chainInstance) []common.Address {
+	var out []common.Address
+	for _, a := range strings.Split(os.Getenv("PROXY_ADDRESSES"), ",") {
+		if a = strings.TrimSpace(a); common.IsHexAddress(a) && ch.isWatched(strings.ToLower(common.HexToAddress(a).Hex())) {
+			out = append(out, common.HexToAddress(a))
+		}
+	}
+	if len(out) > 0 {
+		return out
+	}
+	return ch.addresses()
+}
+
+// proxySlotState slotun bilinen değeri ve bu değerin gözlendiği blok
+type proxySlotState struct {
+	Value common.Address `json:"value"`
+	Block uint64         `json:"block,omitempty"`
+}
+
+var (
+	// zincir:adres:slot -> bilinen değer (dosyada kalıcı; yeniden başlatma sırasındaki değişiklikler de yakalanır)
+	proxyState       = make(map[string]proxySlotState)
+	proxyStateMu     sync.Mutex
+	proxyStateLoaded bool
+)
+
+func proxyStateKey(chain string, addr common.Address, slot string) string {
+	return strings.ToLower(chain + ":" + addr.Hex() + ":" + slot)
+}
+
+// loadProxyStateLocked dosyadaki slot değerlerini bir kez belleğe alır (proxyStateMu tutulmalı).
+// Blok bilgisi olmayan eski biçim (anahtar -> adres) de okunur.
+func loadProxyStateLocked() {
+	if proxyStateLoaded {
+		return
+	}
+	proxyStateLoaded = true
+	b, err := os.ReadFile(getProxyStateFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Proxy slot dosyası okunamadı: %v", err)
+		}
+		return
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		log.Printf("⚠️ Proxy slot dosyası parse edilemedi: %v", err)
+		return
+	}
+	for k, v := range raw {
+		var st proxySlotState
+		if err := json.Unmarshal(v, &st); err != nil {
+			var addr common.Address
+			if err := json.Unmarshal(v, &addr); err != nil {
+				continue
+			}
+			st = proxySlotState{Value: addr}
+		}
+		proxyState[k] = st
+	}
+}
+
+// writeProxyStateLocked slot değerlerini atomik olarak (tmp + rename) diske yazar
+func writeProxyStateLocked() error {
+	path := getProxyStateFile()
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(proxyState, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// lookupProxySlot slotun bilinen değerini (kaydetmeden) döner
+func lookupProxySlot(ch *chainInstance, addr common.Address, slot string) (proxySlotState, bool) {
+	proxyStateMu.Lock()
+	defer proxyStateMu.Unlock()
+	loadProxyStateLocked()
+	st, ok := proxyState[proxyStateKey(ch.name(), addr, slot)]
+	return st, ok
+}
+
+// rememberProxySlot slotun verilen blokta gözlenen değerini kaydeder. Daha yeni bir blokta gözlenmiş
+// değerin üzerine yazılmaz.
+func rememberProxySlot(ch *chainInstance, addr common.Address, slot string, value common.Address, block uint64) {
+	proxyStateMu.Lock()
+	defer proxyStateMu.Unlock()
+	rememberProxySlotLocked(proxyStateKey(ch.name(), addr, slot), value, block)
+}
+
+// rememberProxySlotLocked değeri kaydeder; önceki kaydı, bilinip bilinmediğini ve yazılıp yazılmadığını
+// döner (proxyStateMu tutulmalı)
+func rememberProxySlotLocked(key string, value common.Address, block uint64) (proxySlotState, bool, bool) {
+	loadProxyStateLocked()
+	prev, known := proxyState[key]
+	if known && (prev.Value == value || prev.Block > block) {
+		return prev, true, false
+	}
+	proxyState[key] = proxySlotState{Value: value, Block: block}
+	if err := writeProxyStateLocked(); err != nil {
+		log.Printf("⚠️ Proxy slot durumu yazılamadı: %v", err)
+	}
+	return prev, known, true
+}
+
+// pendingProxySlot bildirimle birlikte kuyruğa giren, dedup sahiplenildikten sonra kaydedilecek proxy logu
+type pendingProxySlot struct {
+	ch *chainInstance
+	lg types.Log
+}
+
+// proxySlotChange proxy logunun slot durumunda yaptığı değişiklik (reorg'da geri almak için)
+type proxySlotChange struct {
+	key     string
+	prev    *proxySlotState // nil: önceden kayıt yoktu
+	written proxySlotState
+	at      time.Time
+}
+
+// Reorg'da geri alınabilsin diye kaydedilen proxy loglarının öncesindeki durum (log blockID → kayıt)
+var proxySlotUndo = make(map[string]proxySlotChange)
+
+// proxySlotOf log proxy eventiyse bildirime eklenecek slot kaydını döner
+func proxySlotOf(ch *chainInstance, lg types.Log) []pendingProxySlot {
+	if !isProxyLog(lg) {
+		return nil
+	}
+	return []pendingProxySlot{{ch: ch, lg: lg}}
+}
+
+// applyProxySlots kuyruğa alınan bildirimin proxy değişikliklerini kaydeder; event ile bildirilen
+// değişiklik slot taramasında tekrar bildirilmez. Replay modunda kalıcı durum değiştirilmez.
+func applyProxySlots(list []pendingProxySlot) {
+	if len(list) == 0 || getEventSourceKind() == eventSourceReplay {
+		return
+	}
+	proxyStateMu.Lock()
+	defer proxyStateMu.Unlock()
+	pruneProxySlotUndoLocked()
+	for _, ps := range list {
+		pc := parseProxyLog(ps.lg)
+		if pc == nil {
+			continue
+		}
+		key := proxyStateKey(ps.ch.name(), ps.lg.Address, pc.slot)
+		prev, known, written := rememberProxySlotLocked(key, pc.current, ps.lg.BlockNumber)
+		if !written {
+			continue
+		}
+		change := proxySlotChange{key: key, written: proxySlotState{Value: pc.current, Block: ps.lg.BlockNumber}, at: time.Now()}
+		if known {
+			change.prev = &prev
+		}
+		proxySlotUndo[logRefOf(ps.ch.name(), ps.lg).blockID()] = change
+	}
+}
+
+// undoProxySlots orphan kalan proxy loglarının kaydettiği değerleri geri alır: kayıt hâlâ o logdan
+// geliyorsa önceki değer geri yüklenir (önceden kayıt yoksa silinir)
+func undoProxySlots(refs []logRef) {
+	proxyStateMu.Lock()
+	defer proxyStateMu.Unlock()
+	changed := false
+	for _, r := range refs {
+		id := r.blockID()
+		change, ok := proxySlotUndo[id]
+		if !ok {
+			continue
+		}
+		delete(proxySlotUndo, id)
+		if cur, has := proxyState[change.key]; !has || cur != change.written {
+			continue
+		}
+		if change.prev != nil {
+			proxyState[change.key] = *change.prev
+		} else {
+			delete(proxyState, change.key)
+		}
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	if err := writeProxyStateLocked(); err != nil {
+		log.Printf("⚠️ Proxy slot durumu yazılamadı: %v", err)
+	}
+}
+
+// pruneProxySlotUndoLocked reorg penceresini (1 saat) aşmış geri alma kayıtlarını siler (proxyStateMu tutulmalı)
+func pruneProxySlotUndoLocked() {
+	cutoff := time.Now().Add(-1 * time.Hour)
+	for k, c := range proxySlotUndo {
+		if c.at.Before(cutoff) {
+			delete(proxySlotUndo, k)
+		}
+	}
+}
+
+// startProxyMonitor izlenen kontratların EIP-1967 implementation/admin/beacon slotlarını periyodik okur;
+// event gelmese de değişen slotu önemli bildirim olarak kuyruğa ekler. İlk okumada değer yalnızca kaydedilir.
+func startProxyMonitor(ctx context.Context, ch *chainInstance, pool *rpcPool) {
+	interval := getProxyCheckInterval()
+	if interval == 0 {
+		return
+	}
+	go func() {
+		// Kod içermeyen adresler (EOA cüzdanlar) bir kez tespit edilip atlanır
+		noCode := make(map[common.Address]bool)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			checkProxySlots(checkCtx, ch, pool, noCode)
+			cancel()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// proxyCheckBlock slotların okunacağı bloğu döner: pipeline checkpoint'inden önemli bildirimlerin
+// onay derinliği kadar geride. Pipeline'ın işlediği eventler bu bloğa kadar kaydedilmiş olduğundan
+// event ile bildirilen değişiklik tekrar bildirilmez; okunan değer de reorg ile geri alınamaz.
+func proxyCheckBlock(ch *chainInstance) (uint64, bool) {
+	cp, ok := getCheckpoint(chainCheckpointName(ch.name()))
+	if !ok || cp.Block == 0 {
+		return 0, false
+	}
+	depth := confirmationsFor(true)
+	if cp.Block <= depth {
+		return 0, false
+	}
+	return cp.Block - depth, true
+}
+
+// checkProxySlots tek tarama turu
+func checkProxySlots(ctx context.Context, ch *chainInstance, pool *rpcPool, noCode map[common.Address]bool) {
+	block, ok := proxyCheckBlock(ch)
+	if !ok {
+		return
+	}
+	client, err := pool.client()
+	if err != nil {
+		return
+	}
+	at := new(big.Int).SetUint64(block)
+	for _, addr := range proxyTargets(ch) {
+		if noCode[addr] {
+			continue
+		}
+		code, err := client.CodeAt(ctx, addr, at)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		if len(code) == 0 {
+			noCode[addr] = true
+			continue
+		}
+		for _, s := range proxySlots {
+			raw, err := client.StorageAt(ctx, addr, s.slot, at)
+			if err != nil {
+				if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+					log.Printf("⚠️ [%s] Proxy slot okunamadı %s %s: %v", ch.label(), addr.Hex(), s.name, err)
+				}
+				continue
+			}
+			value := common.BytesToAddress(raw)
+			prev, known := lookupProxySlot(ch, addr, s.name)
+			if !known {
+				rememberProxySlot(ch, addr, s.name, value, block)
+				continue
+			}
+			// Bilinen değer daha yeni bir bloktan (pipeline eventi) ya da değişiklik yok
+			if prev.Block > block || prev.Value == value {
+				continue
+			}
+			log.Printf("🚨 [%s] Proxy slotu değişti (blok=%d): %s %s %s → %s", ch.label(), block, addr.Hex(), s.name, prev.Value.Hex(), value.Hex())
+			if err := enqueueNotification(ctx, proxySlotNotification(ch, addr, s.name, prev.Value, value, block)); err != nil {
+				// Değer kaydedilmez: sonraki turda tekrar bildirilir
+				log.Printf("⚠️ [%s] Proxy slot bildirimi kuyruğa alınamadı: %v", ch.label(), err)
+				return
+			}
+			rememberProxySlot(ch, addr, s.name, value, block)
+		}
+	}
+}
+
+// proxySlotNotification slot taramasında tespit edilen değişikliğin bildirimi
+func proxySlotNotification(ch *chainInstance, addr common.Address, slot string, prev, value common.Address, block uint64) notificationItem {
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("🛡️ **Proxy:** `%s`\n", labelAddress(ch, addr)))
+	body.WriteString(fmt.Sprintf("%s **%s:** `%s → %s`\n", slotEmoji(slot), strings.ToUpper(slot[:1])+slot[1:], labelAddress(ch, prev), labelAddress(ch, value)))
+	body.WriteString("🔎 **Kaynak:** `EIP-1967 slot taraması`\n")
+	body.WriteString(txDetails{block: block}.footer())
+	title := "🔴 [" + ch.category(addr) + "] ProxySlotChanged"
+	return notificationItem{title: ch.titled(title), body: body.String(), time: time.Now()}
+}
@@ -0,0 +1,377 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// logRef bir bildirimin dayandığı on-chain kaydı (log ya da native tx) tanımlar
+type logRef struct {
+	chain       string // zincir adı
+	key         string // txHash:logIndex (native için txHash:native)
+	blockNumber uint64
+	blockHash   common.Hash
+}
+
+// id kaydın zincirler arası tekil kimliğini döner (chain:txHash:logIndex)
+func (r logRef) id() string {
+	return r.chain + ":" + r.key
+}
+
+// blockID kaydın belirli bir bloktaki kimliğini döner (chain:txHash:logIndex@blockHash).
+// Reorg sonrası aynı tx yeni kanonik blokta aynı hash ve index ile tekrar yer alabildiği
+// için orphan ve gönderim kayıtları blok hash'iyle birlikte tutulur
+func (r logRef) blockID() string {
+	return r.id() + "@" + strings.ToLower(r.blockHash.Hex())
+}
+
+// sentRef bot üzerinden gönderilmiş ana mesajın konumu
+type sentRef struct {
+	chatID    int
+	messageID int
+	title     string
+	text      string // gönderilen tam mesaj metni (düzenleme için)
+}
+
+func logRefOf(chain string, lg types.Log) logRef {
+	return logRef{
+		chain:       chain,
+		key:         fmt.Sprintf("%s:%d", strings.ToLower(lg.TxHash.Hex()), lg.Index),
+		blockNumber: lg.BlockNumber,
+		blockHash:   lg.BlockHash,
+	}
+}
+
+func nativeRefOf(chain string, txHash common.Hash, blockNumber uint64, blockHash common.Hash) logRef {
+	return logRef{
+		chain:       chain,
+		key:         strings.ToLower(txHash.Hex()) + ":native",
+		blockNumber: blockNumber,
+		blockHash:   blockHash,
+	}
+}
+
+// internalRefOf trace ile bulunan internal native transferin kaydı (aynı tx'te birden fazla olabilir)
+func internalRefOf(chain string, txHash common.Hash, traceIndex int, blockNumber uint64, blockHash common.Hash) logRef {
+	return logRef{
+		chain:       chain,
+		key:         fmt.Sprintf("%s:internal:%d", strings.ToLower(txHash.Hex()), traceIndex),
+		blockNumber: blockNumber,
+		blockHash:   blockHash,
+	}
+}
+
+var (
+	reorgMu sync.Mutex
+	// Bildirim üretmiş bloklar: zincir -> blok no -> hash -> o bloktaki kayıtlar
+	reorgTrackedBlocks = make(map[string]map[uint64]map[common.Hash][]logRef)
+	// Gönderilmiş bildirimler: kayıt blok kimliği -> mesaj konumu
+	reorgSent = make(map[string]sentRef)
+	// Orphan olmuş kayıtlar, blok kimliğiyle (pending bildirimler gönderilmeden düşürülür)
+	reorgOrphaned = make(map[string]time.Time)
+	// Reorg sonrası pipeline'ların yeniden taraması gereken en küçük blok
+	reorgRewinds = make(map[string]uint64)
+)
+
+// getReorgCheckDepth head'den geriye kaç bloğun hash kontrolü yapılacağını döner
+func getReorgCheckDepth() uint64 {
+	if v := strings.TrimSpace(os.Getenv("REORG_CHECK_DEPTH")); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 64
+}
+
+// getReorgCheckInterval reorg kontrol periyodunu döner (saniye)
+func getReorgCheckInterval() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("REORG_CHECK_INTERVAL")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 15 * time.Second
+}
+
+// trackRefs kuyruğa alınan bildirimin kayıtlarını blok hash'leriyle birlikte izlemeye alır
+func trackRefs(refs []logRef) {
+	reorgMu.Lock()
+	defer reorgMu.Unlock()
+	for _, r := range refs {
+		if r.blockNumber == 0 {
+			continue
+		}
+		blocks, ok := reorgTrackedBlocks[r.chain]
+		if !ok {
+			blocks = make(map[uint64]map[common.Hash][]logRef)
+			reorgTrackedBlocks[r.chain] = blocks
+		}
+		byHash, ok := blocks[r.blockNumber]
+		if !ok {
+			byHash = make(map[common.Hash][]logRef)
+			blocks[r.blockNumber] = byHash
+		}
+		byHash[r.blockHash] = append(byHash[r.blockHash], r)
+	}
+}
+
+// markSent gönderilmiş mesajın konumunu kayıtlara bağlar
+func markSent(refs []logRef, sent sentRef) {
+	if sent.messageID == 0 {
+		return
+	}
+	reorgMu.Lock()
+	defer reorgMu.Unlock()
+	for _, r := range refs {
+		reorgSent[r.blockID()] = sent
+	}
+}
+
+// isOrphaned kaydın reorg ile geçersiz kalıp kalmadığını döner
+func isOrphaned(r logRef) bool {
+	reorgMu.Lock()
+	defer reorgMu.Unlock()
+	_, ok := reorgOrphaned[r.blockID()]
+	return ok
+}
+
+// dropOrphaned henüz gönderilmemiş bildirimlerden orphan kayıtlara dayananları ayıklar
+func dropOrphaned(items []notificationItem) []notificationItem {
+	out := items[:0]
+	for _, it := range items {
+		orphan := len(it.refs) > 0
+		for _, r := range it.refs {
+			if !isOrphaned(r) {
+				orphan = false
+				break
+			}
+		}
+		if orphan {
+			log.Printf("🗑️ Reorg: gönderilmemiş bildirim düşürüldü: %s", it.title)
+			continue
+		}
+		out = append(out, it)
+	}
+	return out
+}
+
+// handleRemovedLog Removed=true ile gelen logu orphan olarak işler
+func handleRemovedLog(chain string, lg types.Log) {
+	log.Printf("♻️ [%s] Reorg: log kaldırıldı (blok=%d, tx=%s, index=%d)", chain, lg.BlockNumber, lg.TxHash.Hex(), lg.Index)
+	orphanRefs([]logRef{logRefOf(chain, lg)})
+}
+
+// orphanRefs kayıtları orphan işaretler; daha önce gönderilmiş olanlar için
+// aynı chat'e "geri alındı" mesajı yollar
+func orphanRefs(refs []logRef) {
+	var toRetract []struct {
+		ref  logRef
+		sent sentRef
+	}
+
+	reorgMu.Lock()
+	for _, r := range refs {
+		if _, done := reorgOrphaned[r.blockID()]; done {
+			continue
+		}
+		reorgOrphaned[r.blockID()] = time.Now()
+		if sent, ok := reorgSent[r.blockID()]; ok {
+			toRetract = append(toRetract, struct {
+				ref  logRef
+				sent sentRef
+			}{r, sent})
+			delete(reorgSent, r.blockID())
+		}
+	}
+	reorgMu.Unlock()
+
+	// Kanonik zincirdeki karşılığı (aynı tx tekrar dahil edildiyse) yeniden bildirilebilsin
+	eventDedup.forget(refs)
+	// Orphan loglardan işlenen onaylar ve proxy değerleri kanonik zincirde yok
+	undoApprovals(refs)
+	undoProxySlots(refs)
+
+	for _, rr := range toRetract {
+		sendRetraction(rr.ref, rr.sent)
+	}
+}
+
+// sendRetraction orphan kalan bildirim için orijinal mesaja yanıt olarak geri alma mesajı gönderir
+func sendRetraction(r logRef, sent sentRef) {
+	bot := getBotInstance()
+	if bot == nil || sent.chatID == 0 {
+		return
+	}
+	parts := strings.SplitN(r.key, ":", 2)
+	txh := parts[0]
+	text := "*" + escapeMarkdownV2("↩️ GERİ ALINDI (reorg)") + "*\n\n" +
+		escapeMarkdownV2(sent.title) + "\n" +
+		fmt.Sprintf("📋 **Tx:** `%s`\n🧱 **Blok:** `%d`\n", txh, r.blockNumber) +
+		escapeMarkdownV2("Bu event zincir yeniden düzenlemesi sonrası kanonik zincirde yer almıyor.")
+	if err := bot.SendReply(sent.chatID, text, sent.messageID); err != nil {
+		log.Printf("❌ Retraction mesajı gönderilemedi (chat=%d): %v", sent.chatID, err)
+		return
+	}
+	log.Printf("↩️ Retraction gönderildi (chat=%d, tx=%s)", sent.chatID, txh)
+}
+
+// checkReorgs zincirin izlenen bloklarının hash'lerini kanonik zincirle karşılaştırır.
+// Uyuşmayan bloklardaki kayıtlar orphan işaretlenir, pipeline için geri sarma noktası kaydedilir.
+func checkReorgs(ctx context.Context, chain string, src EventSource) {
+	head, err := src.BlockNumber(ctx)
+	if err != nil {
+		return
+	}
+	depth := getReorgCheckDepth()
+
+	reorgMu.Lock()
+	tracked := reorgTrackedBlocks[chain]
+	blocks := make([]uint64, 0, len(tracked))
+	for bn := range tracked {
+		if head > depth && bn < head-depth {
+			// Yeterince derin: artık izlemeye gerek yok
+			delete(tracked, bn)
+			continue
+		}
+		blocks = append(blocks, bn)
+	}
+	reorgMu.Unlock()
+
+	var minOrphan uint64
+	for _, bn := range blocks {
+		canonical, err := src.HeaderHash(ctx, bn)
+		if err != nil {
+			continue
+		}
+
+		var orphaned []logRef
+		reorgMu.Lock()
+		for h, refs := range tracked[bn] {
+			if h == canonical || h == (common.Hash{}) {
+				continue
+			}
+			orphaned = append(orphaned, refs...)
+			delete(tracked[bn], h)
+		}
+		reorgMu.Unlock()
+
+		if len(orphaned) > 0 {
+			log.Printf("♻️ [%s] Reorg tespit edildi: blok=%d, %d kayıt orphan", chain, bn, len(orphaned))
+			orphanRefs(orphaned)
+			if minOrphan == 0 || bn < minOrphan {
+				minOrphan = bn
+			}
+		}
+	}
+
+	if minOrphan > 0 {
+		requestRewind(chain, minOrphan)
+	}
+}
+
+// requestRewind zincirin pipeline'ını verilen bloktan itibaren yeniden taramaya yönlendirir
+func requestRewind(chain string, fromBlock uint64) {
+	name := chainCheckpointName(chain)
+	cp, ok := getCheckpoint(name)
+	if !ok || cp.Block < fromBlock {
+		return
+	}
+	rewindCheckpoint(name, fromBlock-1)
+	reorgMu.Lock()
+	if cur, ok := reorgRewinds[name]; !ok || fromBlock < cur {
+		reorgRewinds[name] = fromBlock
+	}
+	reorgMu.Unlock()
+}
+
+// consumeRewind pipeline için bekleyen geri sarma noktasını döner ve temizler
+func consumeRewind(name string) (uint64, bool) {
+	reorgMu.Lock()
+	defer reorgMu.Unlock()
+	bn, ok := reorgRewinds[name]
+	if ok {
+		delete(reorgRewinds, name)
+	}
+	return bn, ok
+}
+
+// verifyCheckpointHash checkpoint bloğunun hash'i kanonik zincirle uyuşmuyorsa
+// checkpoint'i REORG_CHECK_DEPTH kadar geri sarar
+func verifyCheckpointHash(ctx context.Context, src EventSource, name string, cp blockCheckpoint) blockCheckpoint {
+	if cp.Hash == "" || common.HexToHash(cp.Hash) == (common.Hash{}) {
+		return cp
+	}
+	canonical, err := src.HeaderHash(ctx, cp.Block)
+	if err != nil || canonical == common.HexToHash(cp.Hash) {
+		return cp
+	}
+	depth := getReorgCheckDepth()
+	back := uint64(0)
+	if cp.Block > depth {
+		back = cp.Block - depth
+	}
+	log.Printf("♻️ [%s] Checkpoint hash'i kanonik zincirle uyuşmuyor (blok=%d), %d bloğa geri sarılıyor", name, cp.Block, back)
+	rewindCheckpoint(name, back)
+	cp.Block = back
+	cp.Hash = ""
+	return cp
+}
+
+// startReorgMonitor zincir için periyodik reorg kontrolünü başlatır. Kaynak pipeline'ınkinden ayrı bir
+// örnek olmalıdır (havuzun aktif sağlayıcısı üzerinden okur)
+func startReorgMonitor(ctx context.Context, chain string, src EventSource) {
+	go func() {
+		ticker := time.NewTicker(getReorgCheckInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			checkCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			checkReorgs(checkCtx, chain, src)
+			cancel()
+			pruneReorgState()
+		}
+	}()
+}
+
+// pruneReorgState eski orphan ve gönderim kayıtlarını temizler
+func pruneReorgState() {
+	cutoff := time.Now().Add(-1 * time.Hour)
+	reorgMu.Lock()
+	defer reorgMu.Unlock()
+	for k, ts := range reorgOrphaned {
+		if ts.Before(cutoff) {
+			delete(reorgOrphaned, k)
+		}
+	}
+	// Gönderim kayıtları yalnızca izlenen bloklar için anlamlı
+	if len(reorgSent) > 5000 {
+		live := make(map[string]bool)
+		for _, blocks := range reorgTrackedBlocks {
+			for _, byHash := range blocks {
+				for _, refs := range byHash {
+					for _, r := range refs {
+						live[r.blockID()] = true
+					}
+				}
+			}
+		}
+		for k := range reorgSent {
+			if !live[k] {
+				delete(reorgSent, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,520 @@
+package listener
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Kayıt türleri (JSONL dosyasında her satır bir kayıt)
+const (
+	recordBlock   = "block"
+	recordTx      = "tx"
+	recordLog     = "log"
+	recordReceipt = "receipt"
+	recordCall    = "call"
+	recordPrice   = "price"
+)
+
+// sourceRecord kayıt dosyasındaki tek satır. Aynı dosyada birden fazla zincir bulunabilir.
+type sourceRecord struct {
+	Type    string           `json:"type"`
+	Chain   string           `json:"chain"`
+	Block   *recordedBlock   `json:"block,omitempty"`
+	Tx      *recordedTx      `json:"tx,omitempty"`
+	Log     *types.Log       `json:"log,omitempty"`
+	Receipt *recordedReceipt `json:"receipt,omitempty"`
+	Call    *recordedCall    `json:"call,omitempty"`
+	Price   *recordedPrice   `json:"price,omitempty"`
+}
+
+type recordedBlock struct {
+	Number     uint64        `json:"number"`
+	Hash       common.Hash   `json:"hash"`
+	ParentHash common.Hash   `json:"parentHash"`
+	Time       uint64        `json:"time,omitempty"` // blok zaman damgası (eski kayıtlarda yok)
+	TxHashes   []common.Hash `json:"txHashes,omitempty"`
+}
+
+// recordedTx izlenen adresi ilgilendiren native transfer (üst seviye ya da internal)
+type recordedTx struct {
+	Hash        common.Hash `json:"hash"`
+	BlockNumber uint64      `json:"blockNumber"`
+	TxIndex     uint        `json:"txIndex"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	Value       string      `json:"value"` // wei, ondalık
+	Internal    bool        `json:"internal,omitempty"`
+	TraceIndex  int         `json:"traceIndex,omitempty"`
+}
+
+// recordedCall izlenen adresi ilgilendiren tx'in input verisi (fonksiyon çözümlemesi için)
+type recordedCall struct {
+	Hash        common.Hash   `json:"hash"`
+	BlockNumber uint64        `json:"blockNumber"`
+	From        string        `json:"from"`
+	To          string        `json:"to"`
+	Input       hexutil.Bytes `json:"input"`
+}
+
+type recordedReceipt struct {
+	TxHash            common.Hash `json:"txHash"`
+	Status            uint64      `json:"status"`
+	GasUsed           uint64      `json:"gasUsed"`
+	EffectiveGasPrice string      `json:"effectiveGasPrice,omitempty"` // wei, ondalık
+}
+
+// recordedPrice canlı akışta token için hesaplanan fiyat ve kaynak dökümü
+type recordedPrice struct {
+	Token  string       `json:"token"`
+	Price  float64      `json:"price"`
+	Quotes []PriceQuote `json:"quotes,omitempty"`
+	At     time.Time    `json:"at"`
+}
+
+// getReplayFile replay kaynağının okuyacağı kayıt dosyasını döner (REPLAY_FILE)
+func getReplayFile() string {
+	return strings.TrimSpace(os.Getenv("REPLAY_FILE"))
+}
+
+// getReplayOutput replay bildirimlerinin yazılacağı dosyayı döner (REPLAY_OUTPUT, boşsa stdout)
+func getReplayOutput() string {
+	return strings.TrimSpace(os.Getenv("REPLAY_OUTPUT"))
+}
+
+// getRecordFile canlı trafiğin kaydedileceği dosyayı döner (RECORD_FILE, boşsa kayıt kapalı)
+func getRecordFile() string {
+	return strings.TrimSpace(os.Getenv("RECORD_FILE"))
+}
+
+// replaySource kayıt dosyasındaki blokları, native transferleri, logları ve receipt'leri
+// bir zincir için bellekten sunar. Dosyadaki son blok head kabul edilir.
+type replaySource struct {
+	chain    *chainInstance
+	blocks   map[uint64]recordedBlock
+	txs      map[uint64][]recordedTx
+	calls    map[uint64][]recordedCall
+	logs     map[uint64][]types.Log
+	receipts map[common.Hash]recordedReceipt
+	first    uint64
+	last     uint64
+}
+
+// loadReplaySource kayıt dosyasını okur ve zincire ait kayıtları bloklara göre gruplar
+func loadReplaySource(path string, ch *chainInstance) (*replaySource, error) {
+	if path == "" {
+		return nil, fmt.Errorf("REPLAY_FILE tanımlı değil")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rs := &replaySource{
+		chain:    ch,
+		blocks:   make(map[uint64]recordedBlock),
+		txs:      make(map[uint64][]recordedTx),
+		calls:    make(map[uint64][]recordedCall),
+		logs:     make(map[uint64][]types.Log),
+		receipts: make(map[common.Hash]recordedReceipt),
+	}
+	seenLogs := make(map[string]bool)
+	seenTxs := make(map[string]bool)
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		if len(strings.TrimSpace(sc.Text())) == 0 {
+			continue
+		}
+		var rec sourceRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d parse edilemedi: %w", path, line, err)
+		}
+		if rec.Chain != ch.name() {
+			continue
+		}
+		switch {
+		case rec.Type == recordBlock && rec.Block != nil:
+			b := *rec.Block
+			rs.blocks[b.Number] = b
+			if rs.first == 0 || b.Number < rs.first {
+				rs.first = b.Number
+			}
+			if b.Number > rs.last {
+				rs.last = b.Number
+			}
+		case rec.Type == recordTx && rec.Tx != nil:
+			key := fmt.Sprintf("%s:%v:%d", rec.Tx.Hash.Hex(), rec.Tx.Internal, rec.Tx.TraceIndex)
+			if !seenTxs[key] {
+				seenTxs[key] = true
+				rs.txs[rec.Tx.BlockNumber] = append(rs.txs[rec.Tx.BlockNumber], *rec.Tx)
+			}
+		case rec.Type == recordCall && rec.Call != nil:
+			rs.calls[rec.Call.BlockNumber] = append(rs.calls[rec.Call.BlockNumber], *rec.Call)
+		case rec.Type == recordLog && rec.Log != nil:
+			key := logRefOf(ch.name(), *rec.Log).key
+			if !seenLogs[key] {
+				seenLogs[key] = true
+				rs.logs[rec.Log.BlockNumber] = append(rs.logs[rec.Log.BlockNumber], *rec.Log)
+			}
+		case rec.Type == recordReceipt && rec.Receipt != nil:
+			rs.receipts[rec.Receipt.TxHash] = *rec.Receipt
+		case rec.Type == recordPrice && rec.Price != nil:
+			storeReplayPrice(ch, *rec.Price)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(rs.blocks) == 0 {
+		return nil, fmt.Errorf("%s içinde %s zinciri için blok kaydı yok", path, ch.name())
+	}
+	log.Printf("📼 [%s] Replay dosyası yüklendi: %d blok (%d-%d), %d log, %d receipt",
+		ch.label(), len(rs.blocks), rs.first, rs.last, len(seenLogs), len(rs.receipts))
+	return rs, nil
+}
+
+func (r *replaySource) BlockNumber(ctx context.Context) (uint64, error) {
+	return r.last, nil
+}
+
+func (r *replaySource) ReportFailure(err error) {}
+
+func (r *replaySource) HeaderHash(ctx context.Context, bn uint64) (common.Hash, error) {
+	b, ok := r.blocks[bn]
+	if !ok {
+		return common.Hash{}, fmt.Errorf("blok %d kayıtta yok", bn)
+	}
+	return b.Hash, nil
+}
+
+// Block kayıttaki bloğu döner. Native transferler mevcut izleme listesine göre yeniden süzülür.
+// Kayıtta olmayan bloklar (boşluk) boş blok olarak döner: hash zinciri kontrolü yapılmaz.
+func (r *replaySource) Block(ctx context.Context, bn uint64) (blockData, error) {
+	b, ok := r.blocks[bn]
+	if !ok {
+		return blockData{}, nil
+	}
+	bd := blockData{hash: b.Hash, parentHash: b.ParentHash, time: b.Time, txHashes: b.TxHashes, calls: make(map[common.Hash]txCall)}
+	for _, c := range r.calls[bn] {
+		if callTouchesWatched(r.chain, c.From, c.To) {
+			bd.calls[c.Hash] = txCall{from: c.From, to: c.To, input: c.Input}
+		}
+	}
+	for _, t := range r.txs[bn] {
+		if !(t.To != "" && r.chain.isWatched(t.To)) && !r.chain.isWatched(t.From) {
+			continue
+		}
+		value, ok := new(big.Int).SetString(t.Value, 10)
+		if !ok {
+			continue
+		}
+		bd.natives = append(bd.natives, nativeTransfer{
+			txHash:     t.Hash,
+			txIndex:    t.TxIndex,
+			from:       t.From,
+			to:         t.To,
+			value:      value,
+			internal:   t.Internal,
+			traceIndex: t.TraceIndex,
+		})
+	}
+	return bd, nil
+}
+
+// Logs kayıttaki logları eth_getLogs kurallarıyla (adres listesi, konumsal topic kümeleri) süzer
+func (r *replaySource) Logs(ctx context.Context, name string, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	var out []types.Log
+	for bn := from; bn <= to; bn++ {
+		for _, lg := range r.logs[bn] {
+			if matchesFilter(lg, q) {
+				out = append(out, lg)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (r *replaySource) Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	rc, ok := r.receipts[txHash]
+	if !ok {
+		return nil, nil
+	}
+	rcpt := &types.Receipt{TxHash: rc.TxHash, Status: rc.Status, GasUsed: rc.GasUsed}
+	if v, ok := new(big.Int).SetString(rc.EffectiveGasPrice, 10); ok {
+		rcpt.EffectiveGasPrice = v
+	}
+	return rcpt, nil
+}
+
+// matchesFilter logun sorguya uyup uymadığını eth_getLogs semantiğiyle kontrol eder
+func matchesFilter(lg types.Log, q ethereum.FilterQuery) bool {
+	if len(q.Addresses) > 0 {
+		found := false
+		for _, a := range q.Addresses {
+			if a == lg.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for i, set := range q.Topics {
+		if len(set) == 0 {
+			continue
+		}
+		if i >= len(lg.Topics) {
+			return false
+		}
+		found := false
+		for _, t := range set {
+			if t == lg.Topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sourceRecorder kaynaklardan dönen verileri tek bir JSONL dosyasına yazar (tüm zincirler ortak)
+type sourceRecorder struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+var (
+	recorderOnce   sync.Once
+	activeRecorder *sourceRecorder
+)
+
+// getRecorder RECORD_FILE tanımlıysa kaydediciyi bir kez açar (dosyanın sonuna ekler)
+func getRecorder() *sourceRecorder {
+	recorderOnce.Do(func() {
+		path := getRecordFile()
+		if path == "" {
+			return
+		}
+		if dir := filepath.Dir(path); dir != "" {
+			_ = os.MkdirAll(dir, 0o755)
+		}
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			log.Printf("⚠️ Kayıt dosyası açılamadı, kayıt kapalı: %v", err)
+			return
+		}
+		activeRecorder = &sourceRecorder{f: f, enc: json.NewEncoder(f)}
+		log.Printf("⏺️ Canlı trafik kaydediliyor: %s", path)
+	})
+	return activeRecorder
+}
+
+func (rec *sourceRecorder) write(r sourceRecord) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.f == nil {
+		return
+	}
+	if err := rec.enc.Encode(r); err != nil {
+		log.Printf("⚠️ Kayıt yazılamadı: %v", err)
+	}
+}
+
+// closeRecorder kayıt dosyasını kapatır
+func closeRecorder() {
+	if activeRecorder == nil {
+		return
+	}
+	activeRecorder.mu.Lock()
+	defer activeRecorder.mu.Unlock()
+	if activeRecorder.f != nil {
+		_ = activeRecorder.f.Close()
+		activeRecorder.f = nil
+	}
+}
+
+// recordingSource başka bir kaynağı sarar ve dönen blokları, transferleri, logları ve receipt'leri
+// replay formatında kaydeder
+type recordingSource struct {
+	EventSource
+	chain string
+	rec   *sourceRecorder
+}
+
+func (s *recordingSource) Block(ctx context.Context, bn uint64) (blockData, error) {
+	bd, err := s.EventSource.Block(ctx, bn)
+	if err != nil {
+		return bd, err
+	}
+	s.rec.write(sourceRecord{Type: recordBlock, Chain: s.chain, Block: &recordedBlock{
+		Number: bn, Hash: bd.hash, ParentHash: bd.parentHash, Time: bd.time, TxHashes: bd.txHashes,
+	}})
+	for _, nt := range bd.natives {
+		s.rec.write(sourceRecord{Type: recordTx, Chain: s.chain, Tx: &recordedTx{
+			Hash: nt.txHash, BlockNumber: bn, TxIndex: nt.txIndex, From: nt.from, To: nt.to,
+			Value: nt.value.String(), Internal: nt.internal, TraceIndex: nt.traceIndex,
+		}})
+	}
+	for h, c := range bd.calls {
+		s.rec.write(sourceRecord{Type: recordCall, Chain: s.chain, Call: &recordedCall{
+			Hash: h, BlockNumber: bn, From: c.from, To: c.to, Input: c.input,
+		}})
+	}
+	return bd, nil
+}
+
+func (s *recordingSource) Logs(ctx context.Context, name string, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	logs, err := s.EventSource.Logs(ctx, name, q, from, to)
+	if err != nil {
+		return logs, err
+	}
+	for i := range logs {
+		s.rec.write(sourceRecord{Type: recordLog, Chain: s.chain, Log: &logs[i]})
+	}
+	return logs, nil
+}
+
+func (s *recordingSource) Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	rcpt, err := s.EventSource.Receipt(ctx, txHash)
+	if err != nil || rcpt == nil {
+		return rcpt, err
+	}
+	rr := &recordedReceipt{TxHash: txHash, Status: rcpt.Status, GasUsed: rcpt.GasUsed}
+	if rcpt.EffectiveGasPrice != nil {
+		rr.EffectiveGasPrice = rcpt.EffectiveGasPrice.String()
+	}
+	s.rec.write(sourceRecord{Type: recordReceipt, Chain: s.chain, Receipt: rr})
+	return rcpt, nil
+}
+
+// recordPriceBreakdown RECORD_FILE açıksa fiyat hesaplamasının sonucunu kaydeder (replay'de ağa çıkmadan
+// aynı fiyatlar kullanılsın diye)
+func recordPriceBreakdown(ch *chainInstance, bd PriceBreakdown) {
+	if bd.Price <= 0 || getEventSourceKind() == eventSourceReplay {
+		return
+	}
+	rec := getRecorder()
+	if rec == nil {
+		return
+	}
+	rec.write(sourceRecord{Type: recordPrice, Chain: ch.name(), Price: &recordedPrice{
+		Token: bd.Token, Price: bd.Price, Quotes: bd.Quotes, At: bd.At,
+	}})
+}
+
+var (
+	// zincir:token -> kayıttaki fiyat. Token için birden çok kayıt varsa her replay aynı sonucu
+	// versin diye ilki kullanılır.
+	replayPrices   = make(map[string]recordedPrice)
+	replayPricesMu sync.Mutex
+)
+
+func storeReplayPrice(ch *chainInstance, p recordedPrice) {
+	replayPricesMu.Lock()
+	defer replayPricesMu.Unlock()
+	key := priceCacheKey(ch, strings.ToLower(p.Token))
+	if _, ok := replayPrices[key]; !ok {
+		replayPrices[key] = p
+	}
+}
+
+// replayPriceProvider replay modunda tek fiyat kaynağı: fiyatları kayıt dosyasından sunar, ağa çıkmaz.
+// Kayıtta olmayan tokenın fiyatı yoktur.
+type replayPriceProvider struct{}
+
+func (replayPriceProvider) Name() string { return "replay" }
+
+func (replayPriceProvider) Price(_ context.Context, ch *chainInstance, addr string) (PriceQuote, error) {
+	replayPricesMu.Lock()
+	defer replayPricesMu.Unlock()
+	p, ok := replayPrices[priceCacheKey(ch, strings.ToLower(addr))]
+	if !ok {
+		return PriceQuote{Detail: "kayıtta yok"}, nil
+	}
+	return PriceQuote{Price: p.Price, Detail: "kayıt " + p.At.Format(time.RFC3339)}, nil
+}
+
+// replayNotification replay çıktısındaki tek satır
+type replayNotification struct {
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Important bool   `json:"important,omitempty"`
+}
+
+// replaySink replay sırasında üretilen bildirimleri JSONL olarak yazar (Telegram'a gönderilmez)
+type replaySink struct {
+	mu  sync.Mutex
+	f   *os.File // stdout ise nil
+	enc *json.Encoder
+}
+
+var activeReplaySink = &replaySink{}
+
+// openReplaySink replay çıktısını açar; dosya her çalıştırmada baştan yazılır
+func openReplaySink(path string) error {
+	s := activeReplaySink
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if path == "" {
+		s.enc = json.NewEncoder(os.Stdout)
+		return nil
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		_ = os.MkdirAll(dir, 0o755)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	s.f, s.enc = f, json.NewEncoder(f)
+	log.Printf("📼 Replay bildirimleri yazılıyor: %s", path)
+	return nil
+}
+
+// writeReplayNotification replay bildirimini çıktıya yazar
+func writeReplayNotification(title, body string, important bool) {
+	s := activeReplaySink
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.enc == nil {
+		log.Printf("📼 Replay bildirimi (çıktı kapalı): %s", title)
+		return
+	}
+	if err := s.enc.Encode(replayNotification{Title: title, Body: body, Important: important}); err != nil {
+		log.Printf("⚠️ Replay bildirimi yazılamadı: %v", err)
+	}
+}
+
+// closeReplaySink replay çıktı dosyasını kapatır (kapanışta bekleyen bildirimler gönderildikten sonra)
+func closeReplaySink() {
+	s := activeReplaySink
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f != nil {
+		_ = s.f.Close()
+	}
+	s.f, s.enc = nil, nil
+}
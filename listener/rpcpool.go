@@ -0,0 +1,322 @@
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// rpcProvider havuzdaki tek RPC sağlayıcısı ve sağlık metrikleri
+type rpcProvider struct {
+	url    string
+	rawURL string
+
+	mu      sync.Mutex
+	client  *ethclient.Client
+	raw     *rpc.Client // HTTP raw client (tx tiplerinden bağımsız blok okuma için)
+	head    uint64
+	latency time.Duration // üstel hareketli ortalama
+	errRate float64       // 0..1 arası üstel hareketli hata oranı
+	lastErr error
+	checked time.Time
+}
+
+// rpcPool birden fazla sağlayıcıyı sağlık skoruna göre sıralar ve çağrıları en sağlıklısına yönlendirir
+type rpcPool struct {
+	name      string // zincir adı (loglar için)
+	mu        sync.Mutex
+	providers []*rpcProvider
+	current   *rpcProvider
+}
+
+// getRPCURLs yapılandırılmış sağlayıcı adreslerini döner.
+// ARBITRUM_RPC (birincil) ve ARBITRUM_RPCS (virgülle ayrılmış ek sağlayıcılar) birleştirilir.
+func getRPCURLs() []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(os.Getenv("ARBITRUM_RPC")+","+os.Getenv("ARBITRUM_RPCS"), ",") {
+		u := strings.TrimSpace(part)
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// getRPCHealthInterval sağlık kontrolü periyodunu döner (RPC_HEALTH_INTERVAL, saniye)
+func getRPCHealthInterval() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("RPC_HEALTH_INTERVAL")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 15 * time.Second
+}
+
+// getRPCMaxHeadLag en iyi head'in ne kadar gerisinde kalan sağlayıcının devre dışı sayılacağını döner (RPC_MAX_HEAD_LAG)
+func getRPCMaxHeadLag() uint64 {
+	if v := strings.TrimSpace(os.Getenv("RPC_MAX_HEAD_LAG")); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 20
+}
+
+// rawURLFor sağlayıcı için raw HTTP adresini türetir (wss/ws → https/http).
+// Açık HTTP adresi (ARBITRUM_HTTP_RPC / httpRpc) yalnızca birincil sağlayıcı için kullanılır.
+func rawURLFor(u, primary, httpRPC string) string {
+	if httpRPC != "" && u == primary {
+		return httpRPC
+	}
+	raw := strings.ReplaceAll(u, "wss://", "https://")
+	return strings.ReplaceAll(raw, "ws://", "http://")
+}
+
+// newRPCPool sağlayıcıları oluşturur. Bağlanamayan sağlayıcılar havuzda kalır ve
+// sağlık kontrolünde tekrar denenir; hiçbirine bağlanılamasa bile hata dönmez.
+func newRPCPool(name string, urls []string, primary, httpRPC string) *rpcPool {
+	pool := &rpcPool{name: name}
+	for _, u := range urls {
+		pool.providers = append(pool.providers, &rpcProvider{url: u, rawURL: rawURLFor(u, primary, httpRPC)})
+	}
+	pool.checkAll(context.Background())
+	return pool
+}
+
+// dial bağlantı yoksa sağlayıcıya bağlanır
+func (p *rpcProvider) dial(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client != nil {
+		return nil
+	}
+	c, err := ethclient.DialContext(ctx, p.url)
+	if err != nil {
+		return err
+	}
+	p.client = c
+	if r, err := rpc.DialContext(ctx, p.rawURL); err == nil {
+		p.raw = r
+	} else {
+		log.Printf("⚠️ Raw HTTP RPC kurulamadı (%s): %v", maskRPCURL(p.rawURL), err)
+	}
+	return nil
+}
+
+// record çağrı sonucunu sağlayıcının metriklerine işler
+func (p *rpcProvider) record(err error, took time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	const alpha = 0.3
+	if err != nil {
+		p.errRate = p.errRate*(1-alpha) + alpha
+		p.lastErr = err
+		return
+	}
+	p.errRate = p.errRate * (1 - alpha)
+	if p.latency == 0 {
+		p.latency = took
+	} else {
+		p.latency = time.Duration(float64(p.latency)*(1-alpha) + float64(took)*alpha)
+	}
+}
+
+// reportFailure pipeline gibi çağıranların sağlayıcı hatasını bildirmesi için
+func (p *rpcProvider) reportFailure(err error) {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	p.record(err, 0)
+}
+
+func (p *rpcProvider) snapshot() (head uint64, latency time.Duration, errRate float64, connected bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.head, p.latency, p.errRate, p.client != nil
+}
+
+// check sağlayıcının head'ini ve gecikmesini ölçer
+func (p *rpcProvider) check(ctx context.Context) {
+	if err := p.dial(ctx); err != nil {
+		p.record(err, 0)
+		return
+	}
+	p.mu.Lock()
+	c := p.client
+	p.mu.Unlock()
+
+	start := time.Now()
+	head, err := c.BlockNumber(ctx)
+	p.record(err, time.Since(start))
+
+	p.mu.Lock()
+	p.checked = time.Now()
+	if err == nil {
+		p.head = head
+	}
+	p.mu.Unlock()
+}
+
+// checkAll tüm sağlayıcıları paralel kontrol eder ve en sağlıklısını seçer
+func (pool *rpcPool) checkAll(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, p := range pool.providers {
+		wg.Add(1)
+		go func(p *rpcProvider) {
+			defer wg.Done()
+			p.check(ctx)
+		}(p)
+	}
+	wg.Wait()
+	pool.selectBest()
+}
+
+// score düşük olan daha sağlıklıdır: head gecikmesi, hata oranı ve gecikme süresi birlikte değerlendirilir
+func (pool *rpcPool) score(p *rpcProvider, maxHead uint64) float64 {
+	head, latency, errRate, connected := p.snapshot()
+	if !connected {
+		return 1e12
+	}
+	lag := float64(0)
+	if maxHead > head {
+		lag = float64(maxHead - head)
+	}
+	s := lag*100 + errRate*1000 + float64(latency.Milliseconds())
+	if maxHead > head && maxHead-head > getRPCMaxHeadLag() {
+		s += 1e6
+	}
+	return s
+}
+
+// selectBest sağlayıcıları skorlar ve gerekirse aktif sağlayıcıyı değiştirir
+func (pool *rpcPool) selectBest() {
+	var maxHead uint64
+	for _, p := range pool.providers {
+		if h, _, _, _ := p.snapshot(); h > maxHead {
+			maxHead = h
+		}
+	}
+	ranked := make([]*rpcProvider, len(pool.providers))
+	copy(ranked, pool.providers)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return pool.score(ranked[i], maxHead) < pool.score(ranked[j], maxHead)
+	})
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	if len(ranked) == 0 {
+		return
+	}
+	best := ranked[0]
+	if _, _, _, connected := best.snapshot(); !connected {
+		pool.current = nil
+		return
+	}
+	if pool.current != best {
+		if pool.current != nil {
+			log.Printf("🔀 [%s] RPC sağlayıcısı değiştirildi: %s → %s", pool.name, maskRPCURL(pool.current.url), maskRPCURL(best.url))
+		} else {
+			log.Printf("✅ [%s] Aktif RPC sağlayıcısı: %s", pool.name, maskRPCURL(best.url))
+		}
+		pool.current = best
+	}
+}
+
+// best aktif (en sağlıklı) sağlayıcıyı döner; bağlı sağlayıcı yoksa nil
+func (pool *rpcPool) best() *rpcProvider {
+	pool.mu.Lock()
+	cur := pool.current
+	pool.mu.Unlock()
+	if cur != nil {
+		if _, _, errRate, _ := cur.snapshot(); errRate < 0.5 {
+			return cur
+		}
+	}
+	// Aktif sağlayıcı üst üste hata veriyorsa bir sonraki sağlık kontrolünü beklemeden yeniden seç
+	pool.selectBest()
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.current
+}
+
+// client aktif sağlayıcının ethclient'ını döner
+func (pool *rpcPool) client() (*ethclient.Client, error) {
+	p := pool.best()
+	if p == nil {
+		return nil, fmt.Errorf("bağlı RPC sağlayıcısı yok")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.client, nil
+}
+
+// startHealthChecks sağlayıcıları ctx iptal edilene kadar periyodik olarak kontrol eder
+func (pool *rpcPool) startHealthChecks(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(getRPCHealthInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			pool.checkAll(ctx)
+			if strings.ToLower(os.Getenv("DEBUG_MODE")) == "true" {
+				for _, p := range pool.providers {
+					head, latency, errRate, connected := p.snapshot()
+					log.Printf("🩺 [%s] RPC %s: bağlı=%v head=%d gecikme=%s hata=%.2f", pool.name, maskRPCURL(p.url), connected, head, latency, errRate)
+				}
+			}
+		}
+	}()
+}
+
+// close tüm sağlayıcı bağlantılarını kapatır
+func (pool *rpcPool) close() {
+	for _, p := range pool.providers {
+		p.mu.Lock()
+		if p.client != nil {
+			p.client.Close()
+			p.client = nil
+		}
+		if p.raw != nil {
+			p.raw.Close()
+			p.raw = nil
+		}
+		p.mu.Unlock()
+	}
+	pool.mu.Lock()
+	pool.current = nil
+	pool.mu.Unlock()
+}
+
+// maskRPCURL API anahtarı içerebilecek yol kısmını loglarda gizler
+func maskRPCURL(u string) string {
+	scheme := ""
+	rest := u
+	if i := strings.Index(u, "://"); i >= 0 {
+		scheme = u[:i+3]
+		rest = u[i+3:]
+	}
+	if i := strings.Index(rest, "/"); i >= 0 && len(rest) > i+1 {
+		return scheme + rest[:i] + "/***"
+	}
+	return u
+}
@@ -0,0 +1,45 @@
+package listener
+
+import (
+	"context"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetShutdownTimeout kapanışta bekleyen işler için tanınan süreyi döner (SHUTDOWN_TIMEOUT, saniye)
+func GetShutdownTimeout() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("SHUTDOWN_TIMEOUT")); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 20 * time.Second
+}
+
+// Shutdown ingestion durduktan sonra çağrılır: bekleyen bildirimleri ctx süresi dolana kadar
+// gönderir ve dedup deposunu diske yazar. Checkpoint'ler her aralıktan sonra zaten yazılır.
+func Shutdown(ctx context.Context) {
+	done := make(chan struct{})
+	select {
+	case notificationDrain <- done:
+		select {
+		case <-done:
+			log.Println("✅ Bekleyen bildirimler gönderildi")
+		case <-ctx.Done():
+			log.Println("⚠️ Kapanış süresi doldu, bazı bildirimler gönderilemedi")
+		}
+	case <-ctx.Done():
+		log.Println("⚠️ Bildirim işlemcisi yanıt vermedi, bekleyen bildirimler gönderilemedi")
+	}
+
+	if err := eventDedup.flush(); err != nil {
+		log.Printf("⚠️ Dedup deposu yazılamadı: %v", err)
+	}
+	if err := flushTokenMeta(); err != nil {
+		log.Printf("⚠️ Token metadata yazılamadı: %v", err)
+	}
+	closeReplaySink()
+}
@@ -0,0 +1,623 @@
+package listener
+
+import (
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Varsayılan imzalar binary'ye gömülür; SIGNATURES_DIR olmadan da (ör. farklı çalışma dizini) eventler adlandırılır
+//
+//go:embed signatures/default.sig
+var defaultSignatures string
+
+// eventVariant bir topic0 için bilinen event tanımlarından biri
+type eventVariant struct {
+	ev abi.Event
+	// layoutKnown: indexed düzeni kaynakta belirtilmiş (ABI ya da "indexed" işaretli imza).
+	// false ise düzen topic sayısından çıkarılır (ilk parametreler indexed).
+	layoutKnown bool
+	named       bool // parametre adları kaynakta var (argN değil)
+}
+
+// methodEntry selector için bilinen fonksiyon tanımı
+type methodEntry struct {
+	m     abi.Method
+	named bool // parametre adları kaynakta var (ABI ya da adlı imza)
+}
+
+// eventRegistry topic0 -> event adı ve tanım tabloları.
+// Yeniden yüklemede yeni tablo baştan kurulup tek seferde değiştirilir; okuyucular yarım tablo görmez.
+type eventRegistry struct {
+	addressNames  map[string]map[string]string    // adres -> topic0 -> ad (ABI dosyalarından)
+	addressEvents map[string]map[string]abi.Event // adres -> topic0 -> tam tanım (ABI dosyalarından)
+	globalNames   map[string]string               // topic0 -> ad (adres bağımsız)
+	globalEvents  map[string][]eventVariant       // topic0 -> tanım varyantları (farklı indexed düzenleri)
+
+	addressMethods map[string]map[string]abi.Method // adres -> 4 byte selector -> fonksiyon tanımı (ABI dosyalarından)
+	globalMethods  map[string][]methodEntry         // selector -> farklı fonksiyon imzaları (ABI'ler ve selector veritabanı)
+
+	stats SignatureStats
+}
+
+// SignatureStats imza kayıt defterinin son yükleme özeti
+type SignatureStats struct {
+	Files      int `json:"files"`
+	Signatures int `json:"signatures"`
+	Topics     int `json:"topics"`
+	ABIEvents  int `json:"abi_events"`
+	ABIMethods int `json:"abi_methods"`
+	Functions  int `json:"functions"`
+	Collisions int `json:"collisions"`
+	Skipped    int `json:"skipped"`
+}
+
+func newEventRegistry() *eventRegistry {
+	return &eventRegistry{
+		addressNames:  map[string]map[string]string{},
+		addressEvents: map[string]map[string]abi.Event{},
+		globalNames:   map[string]string{},
+		globalEvents:  map[string][]eventVariant{},
+
+		addressMethods: map[string]map[string]abi.Method{},
+		globalMethods:  map[string][]methodEntry{},
+	}
+}
+
+var (
+	eventRegistryMu sync.RWMutex
+	events          = newEventRegistry()
+	eventsLoaded    bool
+)
+
+// getSignaturesDir ek imza dosyalarının klasörünü döner (SIGNATURES_DIR, varsayılan listener/signatures)
+func getSignaturesDir() string {
+	if v := strings.TrimSpace(os.Getenv("SIGNATURES_DIR")); v != "" {
+		return v
+	}
+	return filepath.Join("listener", "signatures")
+}
+
+// ReloadSignatures gömülü imzaları, SIGNATURES_DIR dosyalarını ve listener/abis ABI'lerini yeniden yükleyip
+// kayıt defterini değiştirir. Hata durumunda mevcut kayıt defteri korunur (ilk yüklemede yüklenebilenler kullanılır).
+func ReloadSignatures() (SignatureStats, error) {
+	r, err := buildEventRegistry()
+	eventRegistryMu.Lock()
+	defer eventRegistryMu.Unlock()
+	if err != nil && eventsLoaded {
+		return events.stats, err
+	}
+	events, eventsLoaded = r, true
+	s := r.stats
+	log.Printf("📚 Event imzaları yüklendi: %d imza, %d topic, %d ABI event, %d ABI fonksiyon, %d selector imzası, %d çakışma, %d atlanan (%d dosya)",
+		s.Signatures, s.Topics, s.ABIEvents, s.ABIMethods, s.Functions, s.Collisions, s.Skipped, s.Files)
+	return s, err
+}
+
+// GetSignatureStats yüklü kayıt defterinin özetini döner
+func GetSignatureStats() SignatureStats {
+	eventRegistryMu.RLock()
+	defer eventRegistryMu.RUnlock()
+	return events.stats
+}
+
+func buildEventRegistry() (*eventRegistry, error) {
+	r := newEventRegistry()
+	if err := r.loadSignatureLines("default.sig", defaultSignatures); err != nil {
+		return r, err
+	}
+	var errs []string
+	if err := r.loadSignaturesDir(getSignaturesDir()); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := r.loadABIs(); err != nil {
+		errs = append(errs, "ABI: "+err.Error())
+	}
+	r.stats.Topics = len(r.globalNames)
+	if len(errs) > 0 {
+		return r, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return r, nil
+}
+
+// loadSignaturesDir klasördeki .sig/.txt (satır başına imza) ve .json (4byte biçimi) dosyalarını yükler.
+// Event imzalarının yanında "function" önekli satırlar ve 4 byte'lık kayıtlar yerel selector veritabanını oluşturur.
+func (r *eventRegistry) loadSignaturesDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil // klasör yoksa yalnızca gömülü imzalar
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s bir klasör değil", dir)
+	}
+	var errs []string
+	walkErr := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(d.Name()))
+		// default.sig binary'ye gömülü olarak zaten yüklendi
+		if d.Name() == "default.sig" || (ext != ".sig" && ext != ".txt" && ext != ".json") {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if ext == ".json" {
+			err = r.loadSignatureJSON(b)
+		} else {
+			err = r.loadSignatureLines(d.Name(), string(b))
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", path, err))
+			return nil
+		}
+		r.stats.Files++
+		return nil
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// loadSignatureLines satır başına bir imza okur; boş satırlar ve # yorumları atlanır
+func (r *eventRegistry) loadSignatureLines(file, content string) error {
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		if err := r.addSignature(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", file, i+1, err)
+		}
+	}
+	return nil
+}
+
+// fourByteEntry 4byte.directory event-signatures kaydı
+type fourByteEntry struct {
+	TextSignature string `json:"text_signature"`
+	HexSignature  string `json:"hex_signature"`
+}
+
+// loadSignatureJSON 4byte biçimindeki dökümü yükler:
+// {"results":[{text_signature, hex_signature}]}, [{text_signature, hex_signature}] ya da {"0x<hash>": "Sig(..)" | ["Sig(..)"]}.
+// 32 byte'lık hash event (topic0), 4 byte'lık hash fonksiyon selector'ıdır.
+// Hash'i imzayla uyuşmayan ya da çözülemeyen kayıtlar atlanır (dökümlerde bozuk kayıt olağan).
+func (r *eventRegistry) loadSignatureJSON(b []byte) error {
+	var entries []fourByteEntry
+	var page struct {
+		Results []fourByteEntry `json:"results"`
+	}
+	var byHash map[string]json.RawMessage
+	switch {
+	case json.Unmarshal(b, &entries) == nil:
+	case json.Unmarshal(b, &page) == nil && page.Results != nil:
+		entries = page.Results
+	case json.Unmarshal(b, &byHash) == nil:
+		for h, raw := range byHash {
+			var one string
+			var many []string
+			if json.Unmarshal(raw, &one) == nil {
+				many = []string{one}
+			} else if json.Unmarshal(raw, &many) != nil {
+				r.stats.Skipped++
+				continue
+			}
+			for _, sig := range many {
+				entries = append(entries, fourByteEntry{TextSignature: sig, HexSignature: h})
+			}
+		}
+	default:
+		return fmt.Errorf("desteklenmeyen imza JSON biçimi")
+	}
+
+	for _, e := range entries {
+		sig, err := parseSignature(e.TextSignature)
+		if err != nil {
+			r.stats.Skipped++
+			continue
+		}
+		switch {
+		case len(e.HexSignature) == 10 && strings.EqualFold(e.HexSignature, "0x"+hex.EncodeToString(sig.selector())):
+			sig.function = true
+		case e.HexSignature == "" || strings.EqualFold(e.HexSignature, sig.topic().Hex()):
+		default:
+			r.stats.Skipped++
+			continue
+		}
+		r.addParsed(sig)
+	}
+	return nil
+}
+
+// sigParam imzadaki tek parametre
+type sigParam struct {
+	typ     string // kanonik tip (ör. uint256, (address,uint8)[])
+	name    string
+	indexed bool
+}
+
+// parsedSignature çözümlenmiş event imzası
+type parsedSignature struct {
+	name    string
+	params  []sigParam
+	indexed bool // en az bir parametre "indexed" işaretli
+	named   bool // parametre adları verilmiş
+
+	function bool // fonksiyon imzası ("function" öneki ya da 4 byte hash)
+}
+
+func (s parsedSignature) canonical() string {
+	types := make([]string, len(s.params))
+	for i, p := range s.params {
+		types[i] = p.typ
+	}
+	return s.name + "(" + strings.Join(types, ",") + ")"
+}
+
+func (s parsedSignature) topic() common.Hash {
+	return crypto.Keccak256Hash([]byte(s.canonical()))
+}
+
+func (s parsedSignature) selector() []byte {
+	return s.topic().Bytes()[:4]
+}
+
+// parseSignature "Name(type,...)", "event Name(type indexed ad, ...)" ya da
+// "function name(type ad, ...) [ekler]" satırını çözer. Öneksiz satırlar event kabul edilir.
+func parseSignature(line string) (parsedSignature, error) {
+	line = strings.TrimSuffix(strings.TrimSpace(line), ";")
+	var sig parsedSignature
+	if strings.HasPrefix(line, "function ") {
+		sig.function = true
+		line = strings.TrimSpace(strings.TrimPrefix(line, "function "))
+	} else {
+		line = strings.TrimSpace(strings.TrimPrefix(line, "event "))
+	}
+	open := strings.Index(line, "(")
+	close := -1
+	depth := 0
+	for i := open; open > 0 && i < len(line); i++ {
+		if line[i] == '(' {
+			depth++
+		} else if line[i] == ')' {
+			if depth--; depth == 0 {
+				close = i
+				break
+			}
+		}
+	}
+	if open <= 0 || close < 0 {
+		return parsedSignature{}, fmt.Errorf("geçersiz imza: %q", line)
+	}
+	// Fonksiyonlarda görünürlük/returns ekleri yok sayılır; anonim eventlerin topic0'ı yoktur
+	if rest := strings.TrimSpace(line[close+1:]); rest != "" && !sig.function {
+		return parsedSignature{}, fmt.Errorf("desteklenmeyen imza eki %q: %q", rest, line)
+	}
+	sig.name = strings.TrimSpace(line[:open])
+	params, err := parseSigParams(line[open+1 : close])
+	if err != nil {
+		return parsedSignature{}, fmt.Errorf("%w: %q", err, line)
+	}
+	sig.params = params
+	for _, p := range params {
+		sig.indexed = sig.indexed || p.indexed
+		sig.named = sig.named || p.name != ""
+	}
+	return sig, nil
+}
+
+// parseSigParams virgülle ayrılmış parametre listesini çözer (tuple içindeki virgüller bölünmez)
+func parseSigParams(s string) ([]sigParam, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("parantez hatası")
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("parantez hatası")
+	}
+	parts = append(parts, s[start:])
+
+	params := make([]sigParam, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		var p sigParam
+		var rest string
+		if strings.HasPrefix(part, "(") {
+			// Tuple: bileşenler özyinelemeli olarak kanonik tipe indirgenir, ardından dizi eki
+			end := strings.LastIndex(part, ")")
+			inner, err := parseSigParams(part[1:end])
+			if err != nil {
+				return nil, err
+			}
+			comps := make([]string, len(inner))
+			for i, c := range inner {
+				comps[i] = c.typ
+			}
+			suffix := part[end+1:]
+			if sp := strings.IndexAny(suffix, " \t"); sp >= 0 {
+				suffix, rest = suffix[:sp], suffix[sp:]
+			}
+			p.typ = "(" + strings.Join(comps, ",") + ")" + suffix
+		} else {
+			fields := strings.Fields(part)
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("boş parametre")
+			}
+			p.typ = canonicalType(fields[0])
+			rest = strings.Join(fields[1:], " ")
+		}
+		for _, f := range strings.Fields(rest) {
+			if f == "memory" || f == "calldata" || f == "storage" || f == "payable" {
+				continue
+			}
+			if f == "indexed" {
+				p.indexed = true
+			} else if p.name == "" {
+				p.name = f
+			} else {
+				return nil, fmt.Errorf("beklenmeyen parametre öğesi %q", f)
+			}
+		}
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// canonicalType uint/int kısaltmalarını kanonik tipe çevirir (uint -> uint256)
+func canonicalType(t string) string {
+	base, suffix := t, ""
+	if i := strings.Index(t, "["); i >= 0 {
+		base, suffix = t[:i], t[i:]
+	}
+	switch base {
+	case "uint", "int":
+		base += "256"
+	}
+	return base + suffix
+}
+
+// addSignature tek imza satırını kayıt defterine ekler
+func (r *eventRegistry) addSignature(line string) error {
+	sig, err := parseSignature(line)
+	if err != nil {
+		return err
+	}
+	r.addParsed(sig)
+	return nil
+}
+
+func (r *eventRegistry) addParsed(sig parsedSignature) {
+	if sig.function {
+		r.addFunction(sig)
+		return
+	}
+	r.stats.Signatures++
+	topic := strings.ToLower(sig.topic().Hex())
+	if _, ok := r.globalNames[topic]; !ok {
+		r.globalNames[topic] = sig.name
+	}
+
+	args, ok := sig.arguments()
+	if !ok {
+		return
+	}
+	r.addVariant(topic, eventVariant{ev: abi.NewEvent(sig.name, sig.name, false, args), layoutKnown: sig.indexed, named: sig.named})
+}
+
+// arguments imza parametrelerini abi.Arguments'a çevirir. Tuple parametreli imzalarda bileşen
+// adları/tipleri abi.Type için yetersiz: false döner (yalnızca ad kaydedilir).
+func (s parsedSignature) arguments() (abi.Arguments, bool) {
+	args := make(abi.Arguments, 0, len(s.params))
+	for i, p := range s.params {
+		if strings.HasPrefix(p.typ, "(") {
+			return nil, false
+		}
+		typ, err := abi.NewType(p.typ, "", nil)
+		if err != nil {
+			return nil, false
+		}
+		name := p.name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		args = append(args, abi.Argument{Name: name, Type: typ, Indexed: p.indexed})
+	}
+	return args, true
+}
+
+// addFunction selector veritabanına fonksiyon imzası ekler. Tuple parametreli imzalar
+// argümansız tanımla eklenir: ad çözülür, argümanlar çözülmez.
+func (r *eventRegistry) addFunction(sig parsedSignature) {
+	r.stats.Functions++
+	args, ok := sig.arguments()
+	m := abi.NewMethod(sig.name, sig.name, abi.Function, "", false, false, args, nil)
+	if !ok {
+		m = abi.Method{Name: sig.name, RawName: sig.name, Type: abi.Function, Sig: sig.canonical(), ID: sig.selector()}
+	}
+	r.addMethod("0x"+hex.EncodeToString(m.ID), methodEntry{m: m, named: sig.named && ok})
+}
+
+// addVariant topic0 için tanım ekler. Aynı indexed düzenindeki tekrarlar birleştirilir (adlı tanım tercih edilir);
+// farklı düzenler (ör. ERC20 ve ERC721 Transfer) ayrı varyant olarak tutulur ve topic sayısına göre seçilir.
+func (r *eventRegistry) addVariant(topic string, v eventVariant) {
+	for i, cur := range r.globalEvents[topic] {
+		if cur.layoutKnown != v.layoutKnown || (v.layoutKnown && !sameIndexedLayout(cur.ev, v.ev)) {
+			continue
+		}
+		if v.named && !cur.named {
+			r.globalEvents[topic][i] = v
+		}
+		return
+	}
+	if len(r.globalEvents[topic]) > 0 && v.layoutKnown {
+		r.stats.Collisions++
+	}
+	r.globalEvents[topic] = append(r.globalEvents[topic], v)
+}
+
+// addABIEvent ABI'den gelen tanımı adrese bağlar ve adres bağımsız varyant olarak da ekler
+func (r *eventRegistry) addABIEvent(addr common.Address, ev abi.Event) {
+	key := strings.ToLower(addr.Hex())
+	topic := strings.ToLower(ev.ID.Hex())
+	if r.addressEvents[key] == nil {
+		r.addressEvents[key] = map[string]abi.Event{}
+		r.addressNames[key] = map[string]string{}
+	}
+	r.addressEvents[key][topic] = ev
+	r.addressNames[key][topic] = ev.RawName
+	if _, ok := r.globalNames[topic]; !ok {
+		r.globalNames[topic] = ev.RawName
+	}
+	r.stats.ABIEvents++
+	r.addVariant(topic, eventVariant{ev: ev, layoutKnown: true, named: true})
+}
+
+// addABIMethod ABI'deki fonksiyonun selector'ını adrese ve genel tabloya ekler
+func (r *eventRegistry) addABIMethod(addr common.Address, m abi.Method) {
+	if len(m.ID) != 4 {
+		return
+	}
+	key := strings.ToLower(addr.Hex())
+	sel := "0x" + hex.EncodeToString(m.ID)
+	if r.addressMethods[key] == nil {
+		r.addressMethods[key] = map[string]abi.Method{}
+	}
+	r.addressMethods[key][sel] = m
+	r.stats.ABIMethods++
+	r.addMethod(sel, methodEntry{m: m, named: true})
+}
+
+// addMethod selector'a imza ekler; aynı imza tekrar gelirse adlı tanım tercih edilir
+func (r *eventRegistry) addMethod(sel string, e methodEntry) {
+	for i, cur := range r.globalMethods[sel] {
+		if cur.m.Sig == e.m.Sig {
+			if e.named && !cur.named {
+				r.globalMethods[sel][i] = e
+			}
+			return
+		}
+	}
+	r.globalMethods[sel] = append(r.globalMethods[sel], e)
+}
+
+// lookupMethod 4 byte selector'ı verilen adreslerin ABI'lerinden (sırayla), bulunamazsa ABI'ler ve
+// selector veritabanından çözer. Tek tanım varsa ok=true ile döner; birden fazla aday varsa
+// (selector çakışması) yalnızca aday imzalar döner.
+func lookupMethod(sel [4]byte, addrs ...common.Address) (m abi.Method, candidates []string, ok bool) {
+	key := "0x" + hex.EncodeToString(sel[:])
+	eventRegistryMu.RLock()
+	defer eventRegistryMu.RUnlock()
+	for _, a := range addrs {
+		if am, found := events.addressMethods[strings.ToLower(a.Hex())]; found {
+			if m, found := am[key]; found {
+				return m, []string{m.Sig}, true
+			}
+		}
+	}
+	entries := events.globalMethods[key]
+	for _, e := range entries {
+		candidates = append(candidates, e.m.Sig)
+	}
+	if len(entries) == 1 {
+		return entries[0].m, candidates, true
+	}
+	return abi.Method{}, candidates, false
+}
+
+// resolveSelector selector'ın fonksiyon imzasını döner; çakışmada adaylar " | " ile birleştirilir
+func resolveSelector(sel [4]byte, addrs ...common.Address) (string, bool) {
+	_, candidates, _ := lookupMethod(sel, addrs...)
+	if len(candidates) == 0 {
+		return "", false
+	}
+	return strings.Join(candidates, " | "), true
+}
+
+// sameIndexedLayout iki tanımın aynı parametrelerinin indexed olup olmadığını karşılaştırır
+func sameIndexedLayout(a, b abi.Event) bool {
+	if len(a.Inputs) != len(b.Inputs) {
+		return false
+	}
+	for i := range a.Inputs {
+		if a.Inputs[i].Indexed != b.Inputs[i].Indexed {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveEventName log adresi ve topic0 için event adını döner (önce adrese özel ABI, sonra genel imzalar)
+func resolveEventName(addr common.Address, topic0 common.Hash) string {
+	topicKey := strings.ToLower(topic0.Hex())
+	eventRegistryMu.RLock()
+	defer eventRegistryMu.RUnlock()
+	if m, ok := events.addressNames[strings.ToLower(addr.Hex())]; ok {
+		if n, ok2 := m[topicKey]; ok2 {
+			return n
+		}
+	}
+	if n, ok := events.globalNames[topicKey]; ok {
+		return n
+	}
+	// kısa hash fallback
+	s := topic0.Hex()
+	if len(s) > 10 {
+		return "Event " + s[:10]
+	}
+	return "Event " + s
+}
+
+// RegisterEventName belirli bir adres + topic0 için ad kaydeder (yeniden yüklemede korunmaz)
+func RegisterEventName(addr common.Address, topic0 common.Hash, name string) {
+	addrKey := strings.ToLower(addr.Hex())
+	topicKey := strings.ToLower(topic0.Hex())
+	eventRegistryMu.Lock()
+	defer eventRegistryMu.Unlock()
+	m, ok := events.addressNames[addrKey]
+	if !ok {
+		m = map[string]string{}
+		events.addressNames[addrKey] = m
+	}
+	m[topicKey] = name
+}
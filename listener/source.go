@@ -0,0 +1,299 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// EventSource blok pipeline'ının veri kaynağı: blok başlıkları, native transferler, loglar ve receipt'ler.
+// Canlı zincir (rpcSource), kayıt dosyası (replaySource) ve kaydedici sarmalayıcı (recordingSource) uygular.
+type EventSource interface {
+	// BlockNumber kaynağın son bloğunu döner; pipeline her turun başında çağırır
+	BlockNumber(ctx context.Context) (uint64, error)
+	// HeaderHash bloğun kanonik hash'ini döner (reorg ve checkpoint kontrolleri için)
+	HeaderHash(ctx context.Context, bn uint64) (common.Hash, error)
+	// Block bloğun hash zincirini ve izlenen adresleri ilgilendiren native transferlerini döner
+	Block(ctx context.Context, bn uint64) (blockData, error)
+	// Logs [from, to] aralığında sorguya uyan logları döner; name sorgunun kimliğidir
+	Logs(ctx context.Context, name string, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error)
+	// Receipt tx'in receipt'ini döner; bilinmiyorsa nil
+	Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	// ReportFailure pipeline'da oluşan kaynak hatasını bildirir (sağlayıcı skorlaması için)
+	ReportFailure(err error)
+}
+
+// Kaynak türleri (EVENT_SOURCE)
+const (
+	eventSourceRPC    = "rpc"
+	eventSourceReplay = "replay"
+)
+
+// getEventSourceKind pipeline'ın veri kaynağını döner (EVENT_SOURCE: rpc | replay, varsayılan rpc)
+func getEventSourceKind() string {
+	if strings.ToLower(strings.TrimSpace(os.Getenv("EVENT_SOURCE"))) == eventSourceReplay {
+		return eventSourceReplay
+	}
+	return eventSourceRPC
+}
+
+// rpcSource havuzdaki en sağlıklı sağlayıcı üzerinden canlı zinciri okur.
+// Sağlayıcı her turun başında (BlockNumber) seçilir; tur içindeki çağrılar aynı sağlayıcıya gider.
+type rpcSource struct {
+	chain *chainInstance
+	pool  *rpcPool
+
+	provider *rpcProvider
+	client   *ethclient.Client
+	raw      *rpc.Client
+
+	// Bazı RPC sağlayıcıları yeni tx tiplerini desteklemiyorsa kalıcı olarak raw moda geçilir
+	useRawOnly      bool
+	loggedRawSwitch bool
+	signer          types.Signer
+
+	// Sorgu başına adaptif eth_getLogs pencereleri
+	logFetchers map[string]*adaptiveLogFetcher
+
+	// Internal native transfer tespiti: istenen yöntem ve sağlayıcı başına tespit edilen destek
+	traceMode    string
+	traceSupport map[*rpcProvider]string
+}
+
+func newRPCSource(ch *chainInstance, pool *rpcPool) *rpcSource {
+	return &rpcSource{chain: ch, pool: pool, traceMode: getNativeTraceMode()}
+}
+
+// useBestProvider havuzdaki en sağlıklı sağlayıcıya geçer; bağlı sağlayıcı yoksa false döner
+func (s *rpcSource) useBestProvider() bool {
+	prov := s.pool.best()
+	if prov == nil {
+		return false
+	}
+	prov.mu.Lock()
+	s.client, s.raw = prov.client, prov.raw
+	prov.mu.Unlock()
+	s.provider = prov
+	return true
+}
+
+func (s *rpcSource) BlockNumber(ctx context.Context) (uint64, error) {
+	// Sağlayıcı değişse de cursor korunur: kaldığı bloktan yeni sağlayıcıyla devam edilir
+	if !s.useBestProvider() {
+		return 0, fmt.Errorf("bağlı RPC sağlayıcısı yok")
+	}
+	head, err := s.client.BlockNumber(ctx)
+	if err != nil {
+		s.provider.reportFailure(err)
+	}
+	return head, err
+}
+
+func (s *rpcSource) ReportFailure(err error) {
+	if s.provider != nil {
+		s.provider.reportFailure(err)
+	}
+}
+
+func (s *rpcSource) HeaderHash(ctx context.Context, bn uint64) (common.Hash, error) {
+	if s.client == nil && !s.useBestProvider() {
+		return common.Hash{}, fmt.Errorf("bağlı RPC sağlayıcısı yok")
+	}
+	hdr, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(bn))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if hdr == nil {
+		return common.Hash{}, fmt.Errorf("blok %d bulunamadı", bn)
+	}
+	return hdr.Hash(), nil
+}
+
+func (s *rpcSource) Receipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return s.client.TransactionReceipt(ctx, txHash)
+}
+
+func (s *rpcSource) Logs(ctx context.Context, name string, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	if s.logFetchers == nil {
+		s.logFetchers = make(map[string]*adaptiveLogFetcher)
+	}
+	f, ok := s.logFetchers[name]
+	if !ok {
+		f = newAdaptiveLogFetcher(name, getLogsMaxRange())
+		s.logFetchers[name] = f
+	}
+	return f.fetch(ctx, s.client, q, from, to)
+}
+
+// Block bloğun hash bilgisini, izlenen adresleri ilgilendiren native transferlerini ve tx çağrı verilerini döner.
+// Sağlayıcı trace destekliyorsa kontrat çağrısı içindeki transferler de eklenir. Internal transferler
+// zenginleştirmedir: trace hatası (zaman aşımı, sağlayıcı limiti) sağlayıcının sağlığına işlenir ve blok
+// internal transferler olmadan işlenir.
+func (s *rpcSource) Block(ctx context.Context, bnum uint64) (blockData, error) {
+	bd, err := s.fetchBlockData(ctx, bnum)
+	if err != nil {
+		return blockData{}, err
+	}
+	internals, err := s.traceInternalTransfers(ctx, bnum, bd.txHashes)
+	if err != nil {
+		if ctx.Err() != nil {
+			return blockData{}, err
+		}
+		s.ReportFailure(err)
+		log.Printf("⚠️ [%s] Blok %d trace alınamadı, internal native transferler atlandı: %v", s.chain.label(), bnum, err)
+		return bd, nil
+	}
+	bd.natives = append(bd.natives, internals...)
+	return bd, nil
+}
+
+func (s *rpcSource) fetchBlockData(ctx context.Context, bnum uint64) (blockData, error) {
+	var gethErr error
+	if !s.useRawOnly {
+		blk, err := s.client.BlockByNumber(ctx, new(big.Int).SetUint64(bnum))
+		if err == nil && blk != nil {
+			return s.blockDataFromGeth(ctx, blk)
+		}
+		gethErr = err
+		// Geth decode hatası "transaction type not supported" ise kalıcı olarak raw moda geç
+		if err != nil && strings.Contains(strings.ToLower(err.Error()), "transaction type not supported") {
+			s.useRawOnly = true
+			if !s.loggedRawSwitch {
+				log.Printf("[native] Geth decode desteklemiyor (yeni tx tipi). Kalıcı olarak RAW moda geçiliyor…")
+				s.loggedRawSwitch = true
+			}
+		} else if err != nil {
+			return blockData{}, err
+		}
+	}
+
+	if s.raw == nil {
+		// Raw client yoksa native transferler çıkarılamaz; hash zinciri için header yeterli
+		hdr, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(bnum))
+		if err != nil {
+			if gethErr != nil {
+				return blockData{}, gethErr
+			}
+			return blockData{}, err
+		}
+		log.Printf("⚠️ [native] Raw RPC yok, blok %d native transferleri atlandı", bnum)
+		return blockData{hash: hdr.Hash(), parentHash: hdr.ParentHash, time: hdr.Time}, nil
+	}
+	return s.blockDataFromRaw(ctx, bnum)
+}
+
+func (s *rpcSource) blockDataFromGeth(ctx context.Context, blk *types.Block) (blockData, error) {
+	bd := blockData{hash: blk.Hash(), parentHash: blk.ParentHash(), time: blk.Time(), calls: make(map[common.Hash]txCall)}
+	for i, tx := range blk.Transactions() {
+		bd.txHashes = append(bd.txHashes, tx.Hash())
+		hasValue := tx.Value() != nil && tx.Value().Sign() > 0
+		if !hasValue && len(tx.Data()) < 4 {
+			continue
+		}
+		toAddr := ""
+		if tx.To() != nil {
+			toAddr = strings.ToLower(tx.To().Hex())
+		}
+		if s.signer == nil {
+			cid, err := s.client.ChainID(ctx)
+			if err != nil {
+				return blockData{}, err
+			}
+			s.signer = types.LatestSignerForChainID(cid)
+		}
+		fromAddress, err := types.Sender(s.signer, tx)
+		if err != nil {
+			continue
+		}
+		fromAddr := strings.ToLower(fromAddress.Hex())
+		if !callTouchesWatched(s.chain, fromAddr, toAddr) {
+			continue
+		}
+		if len(tx.Data()) >= 4 {
+			bd.calls[tx.Hash()] = txCall{from: fromAddr, to: toAddr, input: tx.Data()}
+		}
+		if !hasValue {
+			continue
+		}
+		bd.natives = append(bd.natives, nativeTransfer{
+			txHash:  tx.Hash(),
+			txIndex: uint(i),
+			from:    fromAddr,
+			to:      toAddr,
+			value:   tx.Value(),
+		})
+	}
+	return bd, nil
+}
+
+func (s *rpcSource) blockDataFromRaw(ctx context.Context, bnum uint64) (blockData, error) {
+	var rawBlock struct {
+		Hash         string `json:"hash"`
+		ParentHash   string `json:"parentHash"`
+		Timestamp    string `json:"timestamp"`
+		Transactions []struct {
+			Hash             string `json:"hash"`
+			TransactionIndex string `json:"transactionIndex"`
+			From             string `json:"from"`
+			To               string `json:"to"`
+			Value            string `json:"value"`
+			Input            string `json:"input"`
+		} `json:"transactions"`
+	}
+	if err := s.raw.CallContext(ctx, &rawBlock, "eth_getBlockByNumber", fmt.Sprintf("0x%x", bnum), true); err != nil {
+		return blockData{}, err
+	}
+	if rawBlock.Hash == "" {
+		return blockData{}, fmt.Errorf("blok %d henüz mevcut değil", bnum)
+	}
+
+	bd := blockData{hash: common.HexToHash(rawBlock.Hash), parentHash: common.HexToHash(rawBlock.ParentHash), calls: make(map[common.Hash]txCall)}
+	if ts, err := hexutil.DecodeUint64(rawBlock.Timestamp); err == nil {
+		bd.time = ts
+	}
+	for i, rtx := range rawBlock.Transactions {
+		bd.txHashes = append(bd.txHashes, common.HexToHash(rtx.Hash))
+		fromAddr := strings.ToLower(rtx.From)
+		toAddr := strings.ToLower(rtx.To)
+		if len(rtx.Input) >= 10 && callTouchesWatched(s.chain, fromAddr, toAddr) {
+			if input, err := hexutil.Decode(rtx.Input); err == nil {
+				bd.calls[common.HexToHash(rtx.Hash)] = txCall{from: fromAddr, to: toAddr, input: input}
+			}
+		}
+		val := new(big.Int)
+		if len(rtx.Value) > 2 && strings.HasPrefix(rtx.Value, "0x") {
+			if _, ok := val.SetString(rtx.Value[2:], 16); !ok {
+				continue
+			}
+		}
+		if val.Sign() <= 0 {
+			continue
+		}
+		if !callTouchesWatched(s.chain, fromAddr, toAddr) {
+			continue
+		}
+		txIndex := uint(i)
+		if n, err := strconv.ParseUint(strings.TrimPrefix(rtx.TransactionIndex, "0x"), 16, 64); err == nil {
+			txIndex = uint(n)
+		}
+		bd.natives = append(bd.natives, nativeTransfer{
+			txHash:  common.HexToHash(rtx.Hash),
+			txIndex: txIndex,
+			from:    fromAddr,
+			to:      toAddr,
+			value:   val,
+		})
+	}
+	return bd, nil
+}
@@ -0,0 +1,290 @@
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ERC-20 metadata selector'ları
+var (
+	symbolSelector   = []byte{0x95, 0xd8, 0x9b, 0x41} // symbol()
+	nameSelector     = []byte{0x06, 0xfd, 0xde, 0x03} // name()
+	decimalsSelector = []byte{0x31, 0x3c, 0xe5, 0x67} // decimals()
+)
+
+var stringArgs = func() abi.Arguments {
+	t, _ := abi.NewType("string", "", nil)
+	return abi.Arguments{{Type: t}}
+}()
+
+// tokenMeta kontrattan okunan token bilgisi. Decimals nil ise decimals() çağrılamadı (ör. NFT koleksiyonu);
+// Missing kayıtları metadata'sı olmayan adreslerdir (EOA, token olmayan kontrat) ve belirli süre sonra tekrar denenir.
+type tokenMeta struct {
+	Symbol    string    `json:"symbol,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Decimals  *int      `json:"decimals,omitempty"`
+	Missing   bool      `json:"missing,omitempty"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+var (
+	tokenMetaCache  = make(map[string]tokenMeta)
+	tokenMetaMu     sync.Mutex
+	tokenMetaLoaded bool
+	tokenMetaDirty  bool
+	// Dosya yazımları cache kilidinin dışında, kendi aralarında sıralı yapılır
+	tokenMetaWriteMu sync.Mutex
+)
+
+// getTokenMetadataFile metadata cache dosyasının yolunu döner (TOKEN_METADATA_FILE)
+func getTokenMetadataFile() string {
+	if v := strings.TrimSpace(os.Getenv("TOKEN_METADATA_FILE")); v != "" {
+		return v
+	}
+	return filepath.Join("data", "token_metadata.json")
+}
+
+// getTokenMetadataRetry metadata'sı bulunamayan adreslerin tekrar deneme süresini döner (TOKEN_METADATA_RETRY_HOURS)
+func getTokenMetadataRetry() time.Duration {
+	if v := strings.TrimSpace(os.Getenv("TOKEN_METADATA_RETRY_HOURS")); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			return time.Duration(f * float64(time.Hour))
+		}
+	}
+	return 24 * time.Hour
+}
+
+// tokenDiscoveryEnabled otomatik metadata keşfinin açık olup olmadığını döner (TOKEN_DISCOVERY, varsayılan açık)
+func tokenDiscoveryEnabled() bool {
+	return strings.ToLower(strings.TrimSpace(os.Getenv("TOKEN_DISCOVERY"))) != "false"
+}
+
+func tokenMetaKey(chain string, addr common.Address) string {
+	return strings.ToLower(chain + ":" + addr.Hex())
+}
+
+// loadTokenMetaLocked cache dosyasını bir kez belleğe alır (tokenMetaMu tutulmalı)
+func loadTokenMetaLocked() {
+	if tokenMetaLoaded {
+		return
+	}
+	tokenMetaLoaded = true
+	b, err := os.ReadFile(getTokenMetadataFile())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️ Token metadata dosyası okunamadı: %v", err)
+		}
+		return
+	}
+	if err := json.Unmarshal(b, &tokenMetaCache); err != nil {
+		log.Printf("⚠️ Token metadata dosyası parse edilemedi: %v", err)
+	}
+}
+
+// flushTokenMeta değişiklik varsa cache'i atomik olarak (tmp + rename) diske yazar. Kilit yalnızca
+// anlık görüntü alınırken tutulur; keşifler yazım sırasında beklemez.
+func flushTokenMeta() error {
+	tokenMetaWriteMu.Lock()
+	defer tokenMetaWriteMu.Unlock()
+
+	tokenMetaMu.Lock()
+	if !tokenMetaDirty {
+		tokenMetaMu.Unlock()
+		return nil
+	}
+	b, err := json.MarshalIndent(tokenMetaCache, "", "  ")
+	tokenMetaDirty = false
+	tokenMetaMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := writeTokenMetaFile(b); err != nil {
+		tokenMetaMu.Lock()
+		tokenMetaDirty = true
+		tokenMetaMu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func writeTokenMetaFile(b []byte) error {
+	path := getTokenMetadataFile()
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// cachedTokenMeta cache'teki kaydı döner; süresi dolmuş Missing kayıtları yok sayılır
+func cachedTokenMeta(ch *chainInstance, addr common.Address) (tokenMeta, bool) {
+	tokenMetaMu.Lock()
+	defer tokenMetaMu.Unlock()
+	loadTokenMetaLocked()
+	m, ok := tokenMetaCache[tokenMetaKey(ch.name(), addr)]
+	if ok && m.Missing && time.Since(m.FetchedAt) > getTokenMetadataRetry() {
+		return tokenMeta{}, false
+	}
+	return m, ok
+}
+
+// storeTokenMeta keşfedilen kaydı cache'e yazar; dosyaya startTokenMetaPersister toplu olarak yazar
+func storeTokenMeta(ch *chainInstance, addr common.Address, m tokenMeta) {
+	tokenMetaMu.Lock()
+	defer tokenMetaMu.Unlock()
+	loadTokenMetaLocked()
+	tokenMetaCache[tokenMetaKey(ch.name(), addr)] = m
+	tokenMetaDirty = true
+}
+
+// startTokenMetaPersister metadata cache'ini ctx iptal edilene kadar periyodik olarak diske yazar
+// (son yazma Shutdown içinde yapılır)
+func startTokenMetaPersister(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(10 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := flushTokenMeta(); err != nil {
+				log.Printf("⚠️ Token metadata yazılamadı: %v", err)
+			}
+		}
+	}()
+}
+
+// lookupTokenMeta token bilgisini cache'ten, yoksa zincirin aktif RPC sağlayıcısından okur.
+// RPC bağlı değilse (replay, API'nin ayrı süreçte çalışması) yalnızca cache kullanılır.
+func lookupTokenMeta(ch *chainInstance, addr common.Address) (tokenMeta, bool) {
+	if m, ok := cachedTokenMeta(ch, addr); ok {
+		return m, !m.Missing
+	}
+	pool := ch.activePool()
+	if !tokenDiscoveryEnabled() || pool == nil {
+		return tokenMeta{}, false
+	}
+	client, err := pool.client()
+	if err != nil || client == nil {
+		return tokenMeta{}, false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return discoverTokenMeta(ctx, ch, client, addr)
+}
+
+// isRevertError çağrının kontrat tarafından geri çevrildiğini (execution reverted) gösteren JSON-RPC
+// hatalarını ayırt eder. Diğer hatalar (429, 5xx, bağlantı, zaman aşımı) geçicidir.
+func isRevertError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if e, ok := err.(rpc.Error); ok && e.ErrorCode() == 3 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "execution reverted") || strings.Contains(msg, "revert") || strings.Contains(msg, "invalid opcode")
+}
+
+// discoverTokenMeta symbol(), name() ve decimals() çağrılarıyla token bilgisini okuyup cache'ler.
+// Yalnızca çağrı gerçekten geri çevrildiğinde ya da boş veri döndüğünde o alan yok kabul edilir; geçici
+// RPC hatasında (429, 5xx, bağlantı kopması) sonuç cache'lenmez, sonraki bildirimde tekrar denenir.
+func discoverTokenMeta(ctx context.Context, ch *chainInstance, caller ethereum.ContractCaller, addr common.Address) (tokenMeta, bool) {
+	transient := false
+	call := func(sel []byte) ([]byte, bool) {
+		out, err := caller.CallContract(ctx, ethereum.CallMsg{To: &addr, Data: sel}, nil)
+		if err != nil {
+			if !isRevertError(err) {
+				transient = true
+			}
+			return nil, false
+		}
+		return out, true
+	}
+	m := tokenMeta{FetchedAt: time.Now()}
+	if out, ok := call(symbolSelector); ok {
+		m.Symbol = decodeStringResult(out)
+	}
+	if out, ok := call(nameSelector); ok {
+		m.Name = decodeStringResult(out)
+	}
+	if out, ok := call(decimalsSelector); ok && len(out) == 32 {
+		if d := new(big.Int).SetBytes(out); d.IsInt64() && d.Int64() <= 36 {
+			n := int(d.Int64())
+			m.Decimals = &n
+		}
+	}
+	if transient || ctx.Err() != nil {
+		return tokenMeta{}, false
+	}
+	m.Missing = m.Symbol == "" && m.Decimals == nil
+	storeTokenMeta(ch, addr, m)
+	if !m.Missing {
+		dec := "?"
+		if m.Decimals != nil {
+			dec = strconv.Itoa(*m.Decimals)
+		}
+		log.Printf("🪙 [%s] Token bilgisi keşfedildi: %s %s (%s), decimals=%s", ch.label(), addr.Hex(), m.Symbol, m.Name, dec)
+	}
+	return m, !m.Missing
+}
+
+// decodeStringResult string ya da bytes32 (ör. MKR, SAI) dönen metadata çağrısını çözer
+func decodeStringResult(out []byte) string {
+	var s string
+	if len(out) > 32 {
+		if vals, err := stringArgs.Unpack(out); err == nil && len(vals) == 1 {
+			s, _ = vals[0].(string)
+		}
+	} else if len(out) == 32 {
+		s = string(out[:strings.IndexByte(string(out)+"\x00", 0)])
+	}
+	s = strings.TrimSpace(s)
+	// Kontrol karakteri ya da geçersiz UTF-8 içeren semboller mesaj biçimini bozmasın
+	if !utf8.ValidString(s) || len(s) > 64 {
+		return ""
+	}
+	for _, r := range s {
+		if r < 0x20 || r == '`' || r == '\\' {
+			return ""
+		}
+	}
+	return s
+}
+
+// discoveredDecimals keşfedilen ondalık sayısını döner
+func discoveredDecimals(ch *chainInstance, addr common.Address) (int, bool) {
+	if m, ok := lookupTokenMeta(ch, addr); ok && m.Decimals != nil {
+		return *m.Decimals, true
+	}
+	return 0, false
+}
+
+// discoveredSymbol keşfedilen sembolü döner
+func discoveredSymbol(ch *chainInstance, addr common.Address) (string, bool) {
+	if m, ok := lookupTokenMeta(ch, addr); ok && m.Symbol != "" {
+		return m.Symbol, true
+	}
+	return "", false
+}
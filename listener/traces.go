@@ -0,0 +1,335 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Trace yöntemleri: kontrat çağrısı içindeki (internal) native transferleri bulmak için
+const (
+	traceModeOff    = "off"
+	traceModeAuto   = "auto"
+	traceModeDebug  = "debug"  // debug_traceBlockByNumber + callTracer (geth/nitro)
+	traceModeParity = "parity" // trace_block (erigon/nethermind/openethereum)
+)
+
+// getNativeTraceMode internal native transfer tespiti için trace yöntemini döner (NATIVE_TRACE_MODE).
+// auto: önce debug_traceBlockByNumber, desteklenmiyorsa trace_block denenir; ikisi de yoksa kapanır.
+func getNativeTraceMode() string {
+	switch v := strings.ToLower(strings.TrimSpace(os.Getenv("NATIVE_TRACE_MODE"))); v {
+	case traceModeOff, traceModeDebug, traceModeParity:
+		return v
+	default:
+		return traceModeAuto
+	}
+}
+
+// isTraceUnsupportedError sağlayıcının trace metodunu sunmadığını gösteren hataları ayırt eder
+func isTraceUnsupportedError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if e, ok := err.(rpc.Error); ok && e.ErrorCode() == -32601 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{
+		"method not found",
+		"does not exist",
+		"not available",
+		"not supported",
+		"unsupported",
+		"not enabled",
+		"not allowed",
+		"unknown method",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// callFrame callTracer çıktısındaki tek çağrı
+type callFrame struct {
+	Type  string      `json:"type"`
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Value string      `json:"value"`
+	Error string      `json:"error"`
+	Calls []callFrame `json:"calls"`
+}
+
+// parityTrace trace_block çıktısındaki tek kayıt
+type parityTrace struct {
+	Type   string `json:"type"`
+	Action struct {
+		CallType      string `json:"callType"`
+		From          string `json:"from"`
+		To            string `json:"to"`
+		Value         string `json:"value"`
+		Address       string `json:"address"`       // suicide
+		RefundAddress string `json:"refundAddress"` // suicide
+		Balance       string `json:"balance"`       // suicide
+	} `json:"action"`
+	Result *struct {
+		Address string `json:"address"` // create
+	} `json:"result"`
+	Error               string `json:"error"`
+	TraceAddress        []int  `json:"traceAddress"`
+	TransactionHash     string `json:"transactionHash"`
+	TransactionPosition *uint  `json:"transactionPosition"`
+}
+
+func parseHexWei(s string) *big.Int {
+	v := new(big.Int)
+	if len(s) > 2 && strings.HasPrefix(s, "0x") {
+		if _, ok := v.SetString(s[2:], 16); !ok {
+			return new(big.Int)
+		}
+	}
+	return v
+}
+
+// traceInternalTransfers bloktaki kontrat çağrısı içi native transferlerden izlenen adresleri
+// ilgilendirenleri döner. Üst seviye (tx.Value) transferler bloktan zaten okunduğu için dahil edilmez.
+// Sağlayıcı trace desteklemiyorsa bu sağlayıcı için tespit kapatılır ve hata dönmez.
+func (s *rpcSource) traceInternalTransfers(ctx context.Context, bnum uint64, txHashes []common.Hash) ([]nativeTransfer, error) {
+	if s.raw == nil || s.traceMode == traceModeOff {
+		return nil, nil
+	}
+	if s.traceSupport == nil {
+		s.traceSupport = make(map[*rpcProvider]string)
+	}
+	mode, known := s.traceSupport[s.provider]
+	if !known {
+		mode = s.traceMode
+	}
+
+	switch mode {
+	case traceModeOff:
+		return nil, nil
+	case traceModeDebug:
+		out, err := s.traceDebug(ctx, bnum, txHashes)
+		if isTraceUnsupportedError(err) {
+			return s.traceUnsupported(mode, err)
+		}
+		if err == nil {
+			s.traceSupport[s.provider] = mode
+		}
+		return out, err
+	case traceModeParity:
+		out, err := s.traceParity(ctx, bnum)
+		if isTraceUnsupportedError(err) {
+			return s.traceUnsupported(mode, err)
+		}
+		if err == nil {
+			s.traceSupport[s.provider] = mode
+		}
+		return out, err
+	default: // auto
+		out, err := s.traceDebug(ctx, bnum, txHashes)
+		if err == nil {
+			s.traceSupport[s.provider] = traceModeDebug
+			log.Printf("🔬 [%s] Internal native transferler debug_traceBlockByNumber ile izleniyor (%s)", s.chain.label(), maskRPCURL(s.provider.url))
+			return out, nil
+		}
+		if !isTraceUnsupportedError(err) {
+			return nil, err
+		}
+		out, err = s.traceParity(ctx, bnum)
+		if err == nil {
+			s.traceSupport[s.provider] = traceModeParity
+			log.Printf("🔬 [%s] Internal native transferler trace_block ile izleniyor (%s)", s.chain.label(), maskRPCURL(s.provider.url))
+			return out, nil
+		}
+		if !isTraceUnsupportedError(err) {
+			return nil, err
+		}
+		return s.traceUnsupported(traceModeAuto, err)
+	}
+}
+
+// traceUnsupported sağlayıcıyı trace desteklemeyen olarak işaretler
+func (s *rpcSource) traceUnsupported(mode string, err error) ([]nativeTransfer, error) {
+	s.traceSupport[s.provider] = traceModeOff
+	log.Printf("⚠️ [%s] Sağlayıcı trace desteklemiyor (%s, mod=%s), internal native transferler bu sağlayıcıda izlenmeyecek: %v",
+		s.chain.label(), maskRPCURL(s.provider.url), mode, err)
+	return nil, nil
+}
+
+// traceDebug debug_traceBlockByNumber (callTracer) çıktısından internal transferleri çıkarır
+func (s *rpcSource) traceDebug(ctx context.Context, bnum uint64, txHashes []common.Hash) ([]nativeTransfer, error) {
+	var res []struct {
+		TxHash string    `json:"txHash"`
+		Result callFrame `json:"result"`
+		Error  string    `json:"error"`
+	}
+	tracer := map[string]interface{}{"tracer": "callTracer"}
+	if err := s.raw.CallContext(ctx, &res, "debug_traceBlockByNumber", fmt.Sprintf("0x%x", bnum), tracer); err != nil {
+		return nil, err
+	}
+
+	var out []nativeTransfer
+	for i, tr := range res {
+		// Revert olan tx'te hiçbir değer transferi gerçekleşmez
+		if tr.Error != "" || tr.Result.Error != "" {
+			continue
+		}
+		var txHash common.Hash
+		switch {
+		case tr.TxHash != "":
+			txHash = common.HexToHash(tr.TxHash)
+		case i < len(txHashes):
+			txHash = txHashes[i]
+		default:
+			continue
+		}
+		// Sıra numarası süzmeden önce her alt çağrıya verilir (bkz. traceParity)
+		seq := 0
+		var walk func(frames []callFrame, reverted bool)
+		walk = func(frames []callFrame, reverted bool) {
+			for _, f := range frames {
+				idx := seq
+				seq++
+				// Revert olan alt çağrı ve altındakiler geri alınır
+				rev := reverted || f.Error != ""
+				typ := strings.ToUpper(f.Type)
+				// DELEGATECALL'daki value çağıranın bağlamıdır, transfer değildir
+				if !rev && typ != "DELEGATECALL" && typ != "STATICCALL" {
+					if nt, ok := s.internalTransfer(txHash, uint(i), idx, f.From, f.To, parseHexWei(f.Value)); ok {
+						out = append(out, nt)
+					}
+				}
+				walk(f.Calls, rev)
+			}
+		}
+		walk(tr.Result.Calls, false)
+	}
+	return out, nil
+}
+
+// traceParity trace_block çıktısından internal transferleri çıkarır
+func (s *rpcSource) traceParity(ctx context.Context, bnum uint64) ([]nativeTransfer, error) {
+	var res []parityTrace
+	if err := s.raw.CallContext(ctx, &res, "trace_block", fmt.Sprintf("0x%x", bnum)); err != nil {
+		return nil, err
+	}
+
+	// Revert olan çağrıların traceAddress önekleri (tx bazında): alt çağrıları da geri alınmıştır
+	reverted := make(map[string][]string)
+	for _, t := range res {
+		if t.Error != "" {
+			reverted[t.TransactionHash] = append(reverted[t.TransactionHash], traceAddressKey(t.TraceAddress))
+		}
+	}
+	isReverted := func(t parityTrace) bool {
+		key := traceAddressKey(t.TraceAddress)
+		for _, prefix := range reverted[t.TransactionHash] {
+			if prefix == "" || key == prefix || strings.HasPrefix(key, prefix+",") {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Sağlayıcıdan bağımsız olarak derinlik öncelikli sıraya koy (tx konumu, sonra traceAddress)
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i], res[j]
+		if (a.TransactionPosition == nil) != (b.TransactionPosition == nil) {
+			return b.TransactionPosition == nil
+		}
+		if a.TransactionPosition != nil && *a.TransactionPosition != *b.TransactionPosition {
+			return *a.TransactionPosition < *b.TransactionPosition
+		}
+		return traceAddressLess(a.TraceAddress, b.TraceAddress)
+	})
+
+	// traceIndex: tx içindeki alt çağrının (üst seviye hariç) derinlik öncelikli sırası. Revert olan,
+	// DELEGATECALL/STATICCALL ve değer taşımayan çağrılar da sayılır; böylece numara debug ve parity
+	// yollarında aynıdır ve dedup anahtarı trace yönteminden bağımsız kalır.
+	var out []nativeTransfer
+	seqs := make(map[string]int)
+	for _, t := range res {
+		// Üst seviye çağrı (traceAddress boş) tx.Value ile zaten yakalanıyor; ödül kayıtlarının tx'i yok
+		if len(t.TraceAddress) == 0 || t.TransactionHash == "" || t.TransactionPosition == nil {
+			continue
+		}
+		seq := seqs[t.TransactionHash]
+		seqs[t.TransactionHash] = seq + 1
+		if isReverted(t) {
+			continue
+		}
+		var from, to string
+		var value *big.Int
+		switch t.Type {
+		case "call":
+			ct := strings.ToLower(t.Action.CallType)
+			if ct == "delegatecall" || ct == "staticcall" {
+				continue
+			}
+			from, to, value = t.Action.From, t.Action.To, parseHexWei(t.Action.Value)
+		case "create":
+			from, value = t.Action.From, parseHexWei(t.Action.Value)
+			if t.Result != nil {
+				to = t.Result.Address
+			}
+		case "suicide":
+			from, to, value = t.Action.Address, t.Action.RefundAddress, parseHexWei(t.Action.Balance)
+		default:
+			continue
+		}
+		if nt, ok := s.internalTransfer(common.HexToHash(t.TransactionHash), *t.TransactionPosition, seq, from, to, value); ok {
+			out = append(out, nt)
+		}
+	}
+	return out, nil
+}
+
+// traceAddressLess traceAddress'leri derinlik öncelikli (ön-sıra) gezinme sırasına göre karşılaştırır
+func traceAddressLess(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+func traceAddressKey(addr []int) string {
+	parts := make([]string, len(addr))
+	for i, n := range addr {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+// internalTransfer değer taşıyan ve izlenen adresi ilgilendiren internal çağrıyı nativeTransfer'e çevirir
+func (s *rpcSource) internalTransfer(txHash common.Hash, txIndex uint, seq int, from, to string, value *big.Int) (nativeTransfer, bool) {
+	if value == nil || value.Sign() <= 0 {
+		return nativeTransfer{}, false
+	}
+	fromAddr := strings.ToLower(from)
+	toAddr := strings.ToLower(to)
+	if !(toAddr != "" && s.chain.isWatched(toAddr)) && !s.chain.isWatched(fromAddr) {
+		return nativeTransfer{}, false
+	}
+	return nativeTransfer{
+		txHash:     txHash,
+		txIndex:    txIndex,
+		from:       fromAddr,
+		to:         toAddr,
+		value:      value,
+		internal:   true,
+		traceIndex: seq,
+	}, true
+}
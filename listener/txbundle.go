@@ -0,0 +1,331 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// getTxBundleEnabled aynı tx'in eventlerinin tek bildirimde toplanıp toplanmayacağını döner (TX_BUNDLE, varsayılan true)
+func getTxBundleEnabled() bool {
+	return strings.ToLower(strings.TrimSpace(os.Getenv("TX_BUNDLE"))) != "false"
+}
+
+// sameTx iki pipeline kaydının aynı işleme ait olup olmadığını döner
+func sameTx(a, b pipelineEvent) bool {
+	return a.blockNumber == b.blockNumber && a.txIndex == b.txIndex
+}
+
+// emitTxGroup tek bir tx'e ait kayıtları işler. Bildirim üretecek birden fazla kayıt varsa
+// tx seviyesinde tek bildirim, aksi halde kayıt başına mevcut bildirimler üretilir.
+func (p *blockPipeline) emitTxGroup(ctx context.Context, group []pipelineEvent) error {
+	if getTxBundleEnabled() {
+		var relevant []pipelineEvent
+		for _, ev := range group {
+			if ev.native != nil || (!ev.lg.Removed && isRelevantLog(p.chain, *ev.lg)) {
+				relevant = append(relevant, ev)
+			}
+		}
+		if len(relevant) > 1 {
+			return p.emitTxBundle(ctx, relevant)
+		}
+	}
+	for _, ev := range group {
+		var err error
+		if ev.native != nil {
+			err = p.emitNative(ctx, *ev.native, ev.blockNumber, ev.blockHash)
+		} else {
+			err = p.emitLog(ctx, *ev.lg)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tokenFlow izlenen cüzdanın tx içindeki tek varlık için net bakiye değişimi
+type tokenFlow struct {
+	symbol   string
+	decimals int
+	amount   *big.Int
+}
+
+// walletFlows cüzdan başına net akışları ilk görülme sırasıyla tutar
+type walletFlows struct {
+	wallets []string
+	tokens  map[string][]string // cüzdan -> varlık anahtarları (sıralı)
+	flows   map[string]map[string]*tokenFlow
+}
+
+func newWalletFlows() *walletFlows {
+	return &walletFlows{tokens: make(map[string][]string), flows: make(map[string]map[string]*tokenFlow)}
+}
+
+func (w *walletFlows) add(wallet, key, symbol string, decimals int, delta *big.Int) {
+	if _, ok := w.flows[wallet]; !ok {
+		w.wallets = append(w.wallets, wallet)
+		w.flows[wallet] = make(map[string]*tokenFlow)
+	}
+	f, ok := w.flows[wallet][key]
+	if !ok {
+		f = &tokenFlow{symbol: symbol, decimals: decimals, amount: new(big.Int)}
+		w.flows[wallet][key] = f
+		w.tokens[wallet] = append(w.tokens[wallet], key)
+	}
+	f.amount.Add(f.amount, delta)
+}
+
+// transfer izlenen taraflar için çıkış (-) ve giriş (+) akışını işler
+func (w *walletFlows) transfer(ch *chainInstance, from, to, key, symbol string, decimals int, value *big.Int) {
+	if ch.isWatched(from) {
+		w.add(from, key, symbol, decimals, new(big.Int).Neg(value))
+	}
+	if to != "" && ch.isWatched(to) {
+		w.add(to, key, symbol, decimals, value)
+	}
+}
+
+// emitTxBundle tx'in tüm ilgili eventlerini log sırasıyla, izlenen cüzdanların net akışlarını,
+// tx durumunu ve gas bilgisini içeren tek bildirim üretir
+func (p *blockPipeline) emitTxBundle(ctx context.Context, group []pipelineEvent) error {
+	ch := p.chain
+	refs := make([]logRef, 0, len(group))
+	var txHash common.Hash
+	for _, ev := range group {
+		if ev.native != nil {
+			refs = append(refs, p.nativeRef(*ev.native, ev.blockNumber, ev.blockHash))
+			txHash = ev.native.txHash
+		} else {
+			refs = append(refs, logRefOf(ch.name(), *ev.lg))
+			txHash = ev.lg.TxHash
+		}
+	}
+	// Daha önce işlenmiş tx (yeniden başlatma, tekrar tarama): fiyat ve receipt çekmeden atla
+	if eventDedup.seenAll(refs) {
+		return nil
+	}
+
+	// Receipt: durum, gas ve tx'in tüm logları (log sırası için)
+	rcpt := p.receipt(ctx, txHash)
+	logs := make([]types.Log, 0, len(group))
+	if rcpt != nil && len(rcpt.Logs) > 0 {
+		for _, lg := range rcpt.Logs {
+			if lg != nil && isRelevantLog(ch, *lg) {
+				logs = append(logs, *lg)
+			}
+		}
+	} else {
+		for _, ev := range group {
+			if ev.lg != nil {
+				logs = append(logs, *ev.lg)
+			}
+		}
+	}
+
+	sym := ch.cfg.NativeSymbol
+	flows := newWalletFlows()
+	var lines []string
+	var risks []string
+	var approvals []pendingApproval
+	var proxyChanges []pendingProxySlot
+	var names []string
+	nameCount := make(map[string]int)
+	maxUSD := 0.0
+	category := ""
+	noteCategory := func(addr string) {
+		if category == "" && ch.isWatched(addr) {
+			category = ch.category(common.HexToAddress(addr))
+		}
+	}
+	addName := func(n string) {
+		if nameCount[n] == 0 {
+			names = append(names, n)
+		}
+		nameCount[n]++
+	}
+
+	// Native transferler (üst seviye ve internal) logların önünde listelenir
+	var nativePrice float64
+	for _, ev := range group {
+		nt := ev.native
+		if nt == nil {
+			continue
+		}
+		if nativePrice == 0 {
+			nativePrice = ch.nativeUSDPrice()
+		}
+		addName("Transfer")
+		noteCategory(nt.from)
+		noteCategory(nt.to)
+		amount := formatTokenAmount(nt.value, 18)
+		usd, _ := new(big.Float).Mul(new(big.Float).SetInt(nt.value), big.NewFloat(nativePrice/1e18)).Float64()
+		if usd > maxUSD {
+			maxUSD = usd
+		}
+		kind := "native"
+		if nt.internal {
+			kind = "internal"
+		}
+		lines = append(lines, fmt.Sprintf("▫️ `%s %s %s` `%s` → `%s` `~$%.2f`", kind, amount, sym, shortAddr(nt.from), shortAddr(nt.to), usd))
+		flows.transfer(ch, nt.from, nt.to, "native", sym, 18, nt.value)
+	}
+
+	for _, lg := range logs {
+		if len(lg.Topics) == 0 {
+			continue
+		}
+		topic0 := lg.Topics[0]
+		switch {
+		case isNFTTransferLog(lg):
+			t := parseNFTTransfer(lg)
+			if t == nil {
+				continue
+			}
+			addName("NFT Transfer")
+			noteCategory(strings.ToLower(t.from.Hex()))
+			noteCategory(strings.ToLower(t.to.Hex()))
+			noteCategory(strings.ToLower(lg.Address.Hex()))
+			lines = append(lines, nftBundleLine(ch, lg, t, flows))
+		case isProxyLog(lg):
+			pc := parseProxyLog(lg)
+			if pc == nil {
+				continue
+			}
+			addName(pc.event)
+			noteCategory(strings.ToLower(lg.Address.Hex()))
+			lines = append(lines, proxyBundleLine(ch, lg, pc))
+			proxyChanges = append(proxyChanges, proxySlotOf(ch, lg)...)
+		case isApprovalLog(lg):
+			a := parseApproval(lg)
+			if a == nil {
+				continue
+			}
+			addName("Approval")
+			noteCategory(strings.ToLower(a.owner.Hex()))
+			line, r := approvalBundleLine(ch, lg, a)
+			lines = append(lines, line)
+			approvals = append(approvals, approvalOf(ch, lg)...)
+			risks = append(risks, r...)
+		case topic0 == transferTopic:
+			d := parseTransferDetails(ch, lg)
+			if d == nil {
+				continue
+			}
+			from, to := strings.ToLower(d.from.Hex()), strings.ToLower(d.to.Hex())
+			tokSym := getAssetSymbol(ch, lg.Address)
+			dec := getTokenDecimals(ch, lg.Address)
+			if tokSym == "" {
+				tokSym = shortAddr(lg.Address.Hex())
+			}
+			addName("Transfer")
+			noteCategory(from)
+			noteCategory(to)
+			if d.usdValue > maxUSD {
+				maxUSD = d.usdValue
+			}
+			line := fmt.Sprintf("▫️ `#%d Transfer %s %s` `%s` → `%s`", lg.Index, formatTokenAmount(d.value, dec), tokSym, shortAddr(from), shortAddr(to))
+			if d.usdValue > 0 {
+				line += fmt.Sprintf(" `~$%.2f`", d.usdValue)
+			}
+			lines = append(lines, line)
+			flows.transfer(ch, from, to, strings.ToLower(lg.Address.Hex()), tokSym, dec, d.value)
+		case topic0 == moduleInstalledTopic:
+			addName("InstallModule")
+			noteCategory(strings.ToLower(lg.Address.Hex()))
+			lines = append(lines, fmt.Sprintf("▫️ `#%d InstallModule` `%s`", lg.Index, shortAddr(lg.Address.Hex())))
+		default:
+			name := resolveEventName(lg.Address, topic0)
+			if strings.EqualFold(name, "DiamondCut") {
+				name = "DiamondCut→InstallModule"
+			}
+			addName(name)
+			noteCategory(strings.ToLower(lg.Address.Hex()))
+			line := fmt.Sprintf("▫️ `#%d %s` `%s`", lg.Index, name, shortAddr(lg.Address.Hex()))
+			if topic0 == diamondCutTopic {
+				if dc, err := decodeDiamondCut(lg); err == nil {
+					line += " `" + summarizeDiamondCut(lg, dc) + "`"
+				}
+			} else if fields, ok := generatedEventFields(ch, lg); ok && len(fields) > 0 {
+				line += " `" + compactDecodedFields(fields) + "`"
+			} else if fields := decodeEventFields(ch, lg); len(fields) > 0 {
+				line += " `" + compactDecodedFields(fields) + "`"
+			}
+			lines = append(lines, line)
+		}
+	}
+	if category == "" {
+		category = "Tx"
+	}
+
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("📋 **Tx:** `%s`\n", txHash.Hex()))
+	body.WriteString(p.callLines(txHash))
+	details := p.detailsOf(rcpt, group[0].blockNumber)
+	body.WriteString(details.lines())
+	// Önem tespiti en büyük transferin USD değerine göre yapılır (gövdedeki ilk $ değeri)
+	if maxUSD > 0 {
+		body.WriteString(fmt.Sprintf("💵 **USD:** `~$%.2f`\n", maxUSD))
+	}
+	body.WriteString(fmt.Sprintf("🧾 **Eventler:** `%d`\n", len(lines)))
+	for _, l := range lines {
+		body.WriteString(l + "\n")
+	}
+	if len(risks) > 0 {
+		body.WriteString(fmt.Sprintf("🚨 **Risk:** `%s`\n", strings.Join(risks, ", ")))
+	}
+	if len(flows.wallets) > 0 {
+		body.WriteString("👛 **Net akış:**\n")
+		for _, wallet := range flows.wallets {
+			var parts []string
+			for _, key := range flows.tokens[wallet] {
+				f := flows.flows[wallet][key]
+				if f.amount.Sign() == 0 {
+					continue
+				}
+				sign := "+"
+				if f.amount.Sign() < 0 {
+					sign = "-"
+				}
+				parts = append(parts, sign+formatTokenAmount(new(big.Int).Abs(f.amount), f.decimals)+" "+f.symbol)
+			}
+			if len(parts) == 0 {
+				parts = append(parts, "0")
+			}
+			label := ch.category(common.HexToAddress(wallet))
+			body.WriteString(fmt.Sprintf("`%s %s`: `%s`\n", label, shortAddr(wallet), strings.Join(parts, ", ")))
+		}
+	}
+	body.WriteString(details.timeLine())
+
+	summary := make([]string, 0, len(names))
+	for _, n := range names {
+		if nameCount[n] > 1 {
+			summary = append(summary, fmt.Sprintf("%s ×%d", n, nameCount[n]))
+		} else {
+			summary = append(summary, n)
+		}
+	}
+	label := "[" + category + "] Tx: " + strings.Join(summary, ", ")
+	bodyStr := body.String()
+	emoji := "🔵"
+	if determineImportance(label, bodyStr) {
+		emoji = "🔴"
+	}
+	item := notificationItem{title: ch.titled(emoji + " " + label), body: bodyStr, time: time.Now(), refs: refs, approvals: approvals, proxySlots: proxyChanges}
+	return enqueueNotification(ctx, item)
+}
+
+// shortAddr adresi 0x1234…abcd biçiminde kısaltır
+func shortAddr(addr string) string {
+	if len(addr) <= 12 {
+		return addr
+	}
+	return addr[:6] + "…" + addr[len(addr)-4:]
+}
@@ -0,0 +1,104 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// receipt tx'in receipt'ini işlenen aralık içinde bir kez çeker (bulunamayan receipt'ler de hatırlanır)
+func (p *blockPipeline) receipt(ctx context.Context, txHash common.Hash) *types.Receipt {
+	if rcpt, ok := p.receipts[txHash]; ok {
+		return rcpt
+	}
+	rcpt, _ := p.src.Receipt(ctx, txHash)
+	if p.receipts != nil {
+		p.receipts[txHash] = rcpt
+	}
+	return rcpt
+}
+
+// blockTime bloğun zamanını döner; aralıkta bilinmiyorsa sıfır zaman
+func (p *blockPipeline) blockTime(bn uint64) time.Time {
+	if ts := p.blockTimes[bn]; ts > 0 {
+		return time.Unix(int64(ts), 0)
+	}
+	return time.Time{}
+}
+
+// txDetails bildirim gövdesinin sonuna biçimleyicilerin eklediği tx bilgileri. Sıfır değer (canlı akış,
+// receipt yok) yalnızca bildirim anını gösteren zaman satırını üretir.
+type txDetails struct {
+	block  uint64
+	status string    // receipt durumu (reverted işaretli); receipt yoksa boş
+	gas    string    // kullanılan gas, fiyat ve ücret
+	at     time.Time // blok zamanı; bilinmiyorsa bildirim anı kullanılır
+}
+
+// details tx'in receipt'inden (aralıkta bir kez çekilir) blok, durum, gas ve blok zamanı bilgisini üretir
+func (p *blockPipeline) details(ctx context.Context, txHash common.Hash, bn uint64) txDetails {
+	return p.detailsOf(p.receipt(ctx, txHash), bn)
+}
+
+// detailsOf receipt'ten tx bilgilerini üretir.
+// Ücretin USD karşılığı "$" içermez: önem tespiti gövdedeki ilk "$" değerini transfer tutarı sayar.
+func (p *blockPipeline) detailsOf(rcpt *types.Receipt, bn uint64) txDetails {
+	d := txDetails{block: bn, at: p.blockTime(bn)}
+	if rcpt == nil {
+		return d
+	}
+	d.status = "success"
+	if rcpt.Status == types.ReceiptStatusFailed {
+		d.status = "❌ reverted"
+	}
+	d.gas = fmt.Sprintf("%d", rcpt.GasUsed)
+	if rcpt.EffectiveGasPrice != nil {
+		sym := p.chain.cfg.NativeSymbol
+		gwei := new(big.Float).Quo(new(big.Float).SetInt(rcpt.EffectiveGasPrice), big.NewFloat(1e9))
+		fee := new(big.Int).Mul(new(big.Int).SetUint64(rcpt.GasUsed), rcpt.EffectiveGasPrice)
+		d.gas += fmt.Sprintf(" @ %s gwei = %s %s", gwei.Text('f', 2), formatWei(fee), sym)
+		if price := p.chain.nativeUSDPrice(); price > 0 {
+			usd, _ := new(big.Float).Mul(new(big.Float).SetInt(fee), big.NewFloat(price/1e18)).Float64()
+			if usd < 0.01 {
+				d.gas += fmt.Sprintf(" ≈ %.4f USD", usd)
+			} else {
+				d.gas += fmt.Sprintf(" ≈ %.2f USD", usd)
+			}
+		}
+	}
+	return d
+}
+
+// lines blok, durum ve gas satırlarını (her biri satır sonuyla) döner
+func (d txDetails) lines() string {
+	var b strings.Builder
+	if d.block > 0 {
+		b.WriteString(fmt.Sprintf("🧱 **Blok:** `%d`\n", d.block))
+	}
+	if d.status != "" {
+		b.WriteString(fmt.Sprintf("📊 **Status:** `%s`\n", d.status))
+	}
+	if d.gas != "" {
+		b.WriteString(fmt.Sprintf("⛽ **Gas:** `%s`\n", d.gas))
+	}
+	return b.String()
+}
+
+// timeLine zaman satırını döner (bootstrap ve gecikmeli taramalarda eventin gerçekleştiği blok zamanı)
+func (d txDetails) timeLine() string {
+	t := d.at
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return fmt.Sprintf("⏰ **Zaman:** `%s`", t.Local().Format("02.01.2006 15:04:05"))
+}
+
+// footer log bildirimlerinin son satırları: tx bilgileri ve zaman
+func (d txDetails) footer() string {
+	return d.lines() + d.timeLine()
+}
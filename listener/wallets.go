@@ -133,6 +133,14 @@ func LoadWallets(profile string) {
 	}
 }
 
+// walletsForProfile profil adına karşılık gelen cüzdan listesini döner
+func walletsForProfile(profile string) []walletEntry {
+	if strings.ToLower(strings.TrimSpace(profile)) == "test" {
+		return testWallets
+	}
+	return prodWallets
+}
+
 // Çalışma anında programatik olarak adres eklemek için yardımcı
 func AddWatchedAddress(addr common.Address, label string) {
 	lower := strings.ToLower(addr.Hex())
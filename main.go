@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"event-listener-backend/internal/app"
@@ -56,6 +63,54 @@ func loadEnvFile(filename string) error {
 	return godotenv.Load(tempFile)
 }
 
+// sleepCtx ctx iptal edilmezse d kadar bekler; iptal edildiyse false döner
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// parseBackfillTime RFC3339 ya da YYYY-MM-DD (UTC) biçimindeki zamanı çözümler
+func parseBackfillTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", v)
+}
+
+// runBackfill "backfill" komutunun argümanlarını çözümler ve geçmiş aralığı indeksler
+func runBackfill(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	chain := fs.String("chain", "", "zincir adı (boşsa varsayılan zincir)")
+	from := fs.Uint64("from", 0, "başlangıç bloğu")
+	to := fs.Uint64("to", 0, "bitiş bloğu (0 = head)")
+	fromTime := fs.String("from-time", "", "başlangıç zamanı (RFC3339 ya da YYYY-MM-DD)")
+	toTime := fs.String("to-time", "", "bitiş zamanı (RFC3339 ya da YYYY-MM-DD)")
+	out := fs.String("out", "", "çıktı dosyası (JSONL)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts := listener.BackfillOptions{Chain: *chain, FromBlock: *from, ToBlock: *to, Output: *out}
+	var err error
+	if opts.FromTime, err = parseBackfillTime(*fromTime); err != nil {
+		return fmt.Errorf("geçersiz -from-time: %w", err)
+	}
+	if opts.ToTime, err = parseBackfillTime(*toTime); err != nil {
+		return fmt.Errorf("geçersiz -to-time: %w", err)
+	}
+	if opts.FromBlock == 0 && opts.FromTime.IsZero() {
+		return fmt.Errorf("-from ya da -from-time gerekli")
+	}
+	return listener.RunBackfill(ctx, opts)
+}
+
 func main() {
 	defer func() {
 		if r := recover(); r != nil {
@@ -117,6 +172,10 @@ func main() {
 		log.Println("✅ ARBITRUM_RPC:", v)
 	}
 
+	if v := os.Getenv("ARBITRUM_RPCS"); v != "" {
+		log.Println("✅ ARBITRUM_RPCS:", len(strings.Split(v, ",")), "sağlayıcı")
+	}
+
 	// Cloud deployment için API ayarları
 	if v := os.Getenv("API_HOST"); v == "" {
 		log.Println("ℹ️ API_HOST ayarlanmamış (varsayılan: 0.0.0.0)")
@@ -137,27 +196,40 @@ func main() {
 		log.Println("🔧 DEBUG_MODE:", v)
 	}
 
+	// SIGINT/SIGTERM gelince iptal edilen kök context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Cüzdan profillerini env'e göre yükle ve logla
 	listener.LoadWalletsFromEnv()
 	listener.LogActiveWallets()
 
+	// Backfill komutu: geçmiş aralığı bildirim göndermeden dosyaya indeksler ve çıkar
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := runBackfill(ctx, os.Args[2:]); err != nil {
+			log.Printf("❌ Backfill hatası: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// HTTP API'yi başlat
 	router := app.SetupAPI()
-	go func() {
-		port := os.Getenv("API_PORT")
-		if port == "" {
-			port = "8080"
-		}
+	port := os.Getenv("API_PORT")
+	if port == "" {
+		port = "8080"
+	}
 
-		// Cloud deployment için host binding
-		host := os.Getenv("API_HOST")
-		if host == "" {
-			host = "0.0.0.0" // Cloud'da tüm interface'leri dinle
-		}
+	// Cloud deployment için host binding
+	host := os.Getenv("API_HOST")
+	if host == "" {
+		host = "0.0.0.0" // Cloud'da tüm interface'leri dinle
+	}
 
-		addr := host + ":" + port
-		log.Printf("🌐 HTTP API başlatılıyor: %s", addr)
-		if err := router.Run(addr); err != nil {
+	srv := &http.Server{Addr: host + ":" + port, Handler: router}
+	go func() {
+		log.Printf("🌐 HTTP API başlatılıyor: %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Printf("❌ HTTP API hatası: %v", err)
 		}
 	}()
@@ -180,7 +252,7 @@ func main() {
 		consecutiveErrors := 0
 		maxConsecutiveErrors := 10
 
-		for {
+		for ctx.Err() == nil {
 			updates, err := bot.GetUpdates(offset)
 			if err != nil {
 				consecutiveErrors++
@@ -189,7 +261,9 @@ func main() {
 				// Conflict hatası için özel işlem
 				if strings.Contains(errMsg, "409 Conflict") {
 					log.Printf("⚠️ Bot conflict hatası - diğer instance çalışıyor olabilir. 30 saniye beklenecek...")
-					time.Sleep(30 * time.Second)
+					if !sleepCtx(ctx, 30*time.Second) {
+						return
+					}
 					consecutiveErrors = 0 // Reset error count
 					continue
 				}
@@ -199,10 +273,12 @@ func main() {
 				// Çok fazla hata varsa daha uzun bekle
 				if consecutiveErrors >= maxConsecutiveErrors {
 					log.Printf("⚠️ Çok fazla hata, 60 saniye beklenecek...")
-					time.Sleep(60 * time.Second)
+					if !sleepCtx(ctx, 60*time.Second) {
+						return
+					}
 					consecutiveErrors = 0
-				} else {
-					time.Sleep(5 * time.Second)
+				} else if !sleepCtx(ctx, 5*time.Second) {
+					return
 				}
 				continue
 			}
@@ -220,7 +296,7 @@ func main() {
 				offset = update.UpdateID + 1
 			}
 
-			time.Sleep(2 * time.Second) // Cloud'da biraz daha yavaş
+			sleepCtx(ctx, 2*time.Second) // Cloud'da biraz daha yavaş
 		}
 	}()
 
@@ -230,13 +306,56 @@ func main() {
 	// Filtreleme mantığını test et
 	listener.TestImportanceFiltering()
 
-	// Event listener'ı başlat
-	go listener.StartEventListener()
+	// SIGHUP: event imzalarını ve ABI'leri yeniden başlatmadan yeniden yükle
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				if _, err := listener.ReloadSignatures(); err != nil {
+					log.Printf("⚠️ İmza yeniden yükleme hatası (mevcut imzalar korunuyor): %v", err)
+				}
+			}
+		}
+	}()
+
+	// Event listener'ı başlat (kök context iptal edilince durur)
+	listenerDone := make(chan struct{})
+	go func() {
+		listener.StartEventListener(ctx)
+		close(listenerDone)
+	}()
 
 	log.Println("🚀 Uygulama başladı!")
 	log.Println("📡 Event dinleme aktif")
 	log.Println("🌐 HTTP API aktif")
 	log.Println("🤖 Telegram bot aktif")
 
-	select {}
+	<-ctx.Done()
+	stop()
+	log.Println("🛑 Kapanış sinyali alındı, servisler durduruluyor...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), listener.GetShutdownTimeout())
+	defer cancel()
+
+	// 1) Ingestion: pipeline'lar mevcut aralığı bitirip checkpoint yazar, RPC bağlantıları kapanır
+	select {
+	case <-listenerDone:
+	case <-shutdownCtx.Done():
+		log.Println("⚠️ Event listener zamanında durmadı")
+	}
+
+	// 2) Bekleyen bildirimler gönderilir, dedup deposu diske yazılır
+	listener.Shutdown(shutdownCtx)
+
+	// 3) HTTP API yeni istek almayı bırakır, açık istekler tamamlanır
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ HTTP API kapatılamadı: %v", err)
+	}
+
+	log.Println("👋 Uygulama kapatıldı")
 }
@@ -66,30 +66,76 @@ func NewTelegramBot() (*TelegramBot, error) {
 
 // SendMessage mesaj gönderir
 func (t *TelegramBot) SendMessage(chatID int, text string) error {
+	_, err := t.SendMessageWithID(chatID, text)
+	return err
+}
+
+// SendMessageWithID mesaj gönderir ve Telegram'ın verdiği message_id'yi döner
+func (t *TelegramBot) SendMessageWithID(chatID int, text string) (int, error) {
+	payload := map[string]interface{}{
+		"chat_id":    chatID,
+		"text":       text,
+		"parse_mode": "MarkdownV2",
+	}
+	return t.postMessage("/sendMessage", payload)
+}
+
+// SendReply belirtilen mesaja yanıt olarak mesaj gönderir (MarkdownV2)
+func (t *TelegramBot) SendReply(chatID int, text string, replyToMessageID int) error {
+	payload := map[string]interface{}{
+		"chat_id":                     chatID,
+		"text":                        text,
+		"parse_mode":                  "MarkdownV2",
+		"reply_to_message_id":         replyToMessageID,
+		"allow_sending_without_reply": true,
+	}
+	_, err := t.postMessage("/sendMessage", payload)
+	return err
+}
+
+// EditMessageText daha önce gönderilmiş bir mesajın metnini günceller (MarkdownV2)
+func (t *TelegramBot) EditMessageText(chatID int, messageID int, text string) error {
 	payload := map[string]interface{}{
 		"chat_id":    chatID,
+		"message_id": messageID,
 		"text":       text,
 		"parse_mode": "MarkdownV2",
 	}
+	_, err := t.postMessage("/editMessageText", payload)
+	return err
+}
 
+// postMessage mesaj döndüren bir Bot API metodunu çağırır ve message_id'yi döner
+func (t *TelegramBot) postMessage(method string, payload map[string]interface{}) (int, error) {
 	body, _ := json.Marshal(payload)
-	url := t.apiBase + "/sendMessage"
+	url := t.apiBase + method
 
 	req, _ := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := t.httpClient.Do(req)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		b, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("telegram API hatası: %s - %s", resp.Status, string(b))
+		return 0, fmt.Errorf("telegram API hatası: %s - %s", resp.Status, string(b))
 	}
 
-	return nil
+	var result struct {
+		OK     bool `json:"ok"`
+		Result struct {
+			MessageID int `json:"message_id"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		// Mesaj gönderildi, yalnızca id okunamadı
+		return 0, nil
+	}
+
+	return result.Result.MessageID, nil
 }
 
 // SendMessageWithKeyboard: parse_mode olmadan, tıklanabilir Reply Keyboard ile mesaj gönderir